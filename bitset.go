@@ -0,0 +1,12 @@
+package bitset
+
+// Hash a bit index (using the SplitMix64 finalizer). RollingHash sums this
+// over the set bits of a Bitset32 or Bitset64.
+func hashIndex(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -2,17 +2,22 @@ package bitset
 
 import (
 	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math"
+	"math/bits"
+	"math/rand"
 )
 
 const (
 	sw_32   uint32 = 32
 	slg2_32 uint32 = 5
 	m1_32   uint32 = 0x55555555 // 0101...
-	m2_32   uint32 = 0x33333333 // 00110011...
-	m4_32   uint32 = 0x0f0f0f0f // 00001111...
 	hff_32  uint32 = 0xffffffff // all ones
+	wb_32   uint32 = 4          // bytes per word
 )
 
 func wordsNeeded32(n uint32) uint32 {
@@ -25,8 +30,11 @@ func wordsNeeded32(n uint32) uint32 {
 }
 
 type Bitset32 struct {
-	n uint32
-	b []uint32
+	n       uint32
+	b       []uint32
+	fixed   bool
+	panics  bool   // whether a fixed bitset panics instead of growing
+	touched uint32 // highest index passed to SetTracking
 }
 
 // Returns the current size of the bitset.
@@ -42,20 +50,84 @@ func (b *Bitset32) Test(i uint32) bool {
 	return ((b.b[i>>slg2_32] & (1 << (i & (sw_32 - 1)))) != 0)
 }
 
+// Expand the bitset to a length of n bits if it is shorter.
+func (b *Bitset32) grow(n uint32) {
+	if n <= b.n {
+		return
+	}
+	nsize := wordsNeeded32(n)
+	l := uint32(len(b.b))
+	if nsize > l {
+		nb := make([]uint32, nsize-l)
+		b.b = append(b.b, nb...)
+	}
+	b.n = n
+}
+
+// Prevent (or allow) the bitset from expanding. While the bitset is fixed,
+// setting or flipping a bit beyond its length does nothing, as do Extend,
+// Push, GrowToPow2 and growing with Resize. In-place operations with a longer
+// set, such as UnionWith, ignore its bits beyond the bitset's length.
+// Operations that return a new bitset, such as Union with a longer set, are
+// not affected, and the bitsets they return are never fixed. Shrinking with
+// Resize, Pop or Compact is still allowed, and decoding into the bitset with
+// UnmarshalBinary, ReadFrom, UnmarshalJSON or GobDecode replaces its length
+// along with its contents.
+func (b *Bitset32) SetFixed(fixed bool) {
+	b.fixed = fixed
+}
+
+// Make a fixed bitset panic, rather than do nothing, when asked to set, flip
+// or add a bit beyond its length.
+func (b *Bitset32) SetFixedPanics(panics bool) {
+	b.panics = panics
+}
+
+// Report an attempt to write bit i beyond the length of a fixed bitset,
+// panicking if SetFixedPanics(true) was called.
+func (b *Bitset32) overflow(i uint32) {
+	if b.panics {
+		panic(fmt.Sprintf("Bitset32 of fixed length %d cannot hold bit %d.", b.n, i))
+	}
+}
+
+// Expand the bitset to the length of ob, or if the bitset is fixed, report
+// any set bit of ob beyond its length.
+func (b *Bitset32) growFor(ob *Bitset32) {
+	if !b.fixed {
+		b.grow(ob.n)
+	} else if i, ok := ob.NextSet(b.n); ok {
+		b.overflow(i)
+	}
+}
+
 // Set bit i to 1.
 func (b *Bitset32) Set(i uint32) {
 	if i >= b.n {
-		nsize := wordsNeeded32(i + 1)
-		l := uint32(len(b.b))
-		if nsize > l {
-			nb := make([]uint32, nsize-l)
-			b.b = append(b.b, nb...)
+		if b.fixed {
+			b.overflow(i)
+			return
 		}
-		b.n = i + 1
+		b.grow(i + 1)
 	}
 	b.b[i>>slg2_32] |= (1 << (i & (sw_32 - 1)))
 }
 
+// Set bit i to 1, and record i if it is the highest index passed to
+// SetTracking so far.
+func (b *Bitset32) SetTracking(i uint32) {
+	if i > b.touched {
+		b.touched = i
+	}
+	b.Set(i)
+}
+
+// Get the highest index ever passed to SetTracking, even if that bit has since
+// been cleared, or 0 if SetTracking has not been called.
+func (b *Bitset32) MaxTouched() uint32 {
+	return b.touched
+}
+
 // Set bit i to 0.
 func (b *Bitset32) Clear(i uint32) {
 	if i >= b.n {
@@ -68,6 +140,7 @@ func (b *Bitset32) Clear(i uint32) {
 func (b *Bitset32) Flip(i uint32) {
 	if i >= b.n {
 		b.Set(i)
+		return
 	}
 	b.b[i>>slg2_32] ^= 1 << (i & (sw_32 - 1))
 }
@@ -102,20 +175,11 @@ func (b *Bitset32) Copy(c *Bitset32) (n uint32) {
 	return
 }
 
-func popCountUint32(x uint32) uint32 {
-	x -= (x >> 1) & m1_32                // put count of each 2 bits into those 2 bits
-	x = (x & m2_32) + ((x >> 2) & m2_32) // put count of each 4 bits into those 4 bits 
-	x = (x + (x >> 4)) & m4_32           // put count of each 8 bits into those 8 bits 
-	x += x >> 8                          // put count of each 16 bits into their lowest 8 bits
-	x += x >> 16                         // put count of each 32 bits into their lowest 8 bits
-	return x & 0x7f
-}
-
 // Get the number of set bits in the bitset.
 func (b *Bitset32) Count() uint32 {
 	sum := uint32(0)
 	for _, w := range b.b {
-		sum += popCountUint32(w)
+		sum += uint32(bits.OnesCount32(w))
 	}
 	return sum
 }
@@ -205,9 +269,20 @@ func (b *Bitset32) isEven() bool {
 
 // Clean last word by setting unused bits to 0.
 func (b *Bitset32) cleanLastWord() {
-	if !b.isEven() {
-		b.b[wordsNeeded32(b.n)-1] &= (hff_32 >> (sw_32 - (b.n % sw_32)))
+	if len(b.b) == 0 {
+		return
+	}
+	b.b[len(b.b)-1] &= b.lastWordMask()
+}
+
+// Get a mask of the bits in the last word that are within the bitset's length.
+func (b *Bitset32) lastWordMask() uint32 {
+	if b.n == 0 {
+		return 0
+	} else if b.isEven() {
+		return hff_32
 	}
+	return hff_32 >> (sw_32 - (b.n % sw_32))
 }
 
 // Return the (local) complement of a bitset (up to n bits).
@@ -222,7 +297,22 @@ func (b *Bitset32) Complement() (result *Bitset32) {
 
 // Returns true if all bits in the bitset are set.
 func (b *Bitset32) All() bool {
-	return b.Count() == b.n
+	return b.IsFull()
+}
+
+// Returns true if every bit within the bitset's length is set.
+func (b *Bitset32) IsFull() bool {
+	if len(b.b) == 0 {
+		return b.n == 0
+	}
+	last := len(b.b) - 1
+	for _, w := range b.b[:last] {
+		if w != hff_32 {
+			return false
+		}
+	}
+	m := b.lastWordMask()
+	return b.b[last]&m == m
 }
 
 // Returns true if no bit in the bitset is set.
@@ -249,16 +339,1966 @@ func (b *Bitset32) String() string {
 	return string(buffer.Bytes())
 }
 
-// Make a new bitset with a starting capacity of n bits. The bitset expands
-// automatically.
-func New32(n uint32) *Bitset32 {
-	nWords := wordsNeeded32(n)
-	if nWords > math.MaxInt32-1 {
-		panic(fmt.Sprintf("Bitset32 needs %d %d-bit words to store %d bits, but slices cannot hold more than %d items. Please use a Bitset64 instead.", nWords, sw_32, n, math.MaxInt32-1))
+// Returns true if the bitset shares its backing array with another bitset,
+// i.e. both refer to the same words with the same length and capacity.
+func (b *Bitset32) SameBacking(ob *Bitset32) bool {
+	if len(b.b) != len(ob.b) || cap(b.b) != cap(ob.b) {
+		return false
 	}
-	b := &Bitset32{
-		n,
-		make([]uint32, nWords),
+	return len(b.b) == 0 || &b.b[0] == &ob.b[0]
+}
+
+// Clear the highest set bits until at most maxBits bits remain set. Returns
+// the number of bits that were cleared.
+func (b *Bitset32) LimitTo(maxBits uint32) uint32 {
+	count := b.Count()
+	if count <= maxBits {
+		return 0
 	}
-	return b
+	excess := count - maxBits
+	left := excess
+	for i := len(b.b) - 1; i >= 0 && left > 0; i-- {
+		c := uint32(bits.OnesCount32(b.b[i]))
+		if c <= left {
+			b.b[i] = 0
+			left -= c
+			continue
+		}
+		for j := int(sw_32) - 1; j >= 0 && left > 0; j-- {
+			if b.b[i]&(1<<uint32(j)) != 0 {
+				b.b[i] &^= 1 << uint32(j)
+				left--
+			}
+		}
+	}
+	return excess
+}
+
+// Clear the lowest set bits until at most maxBits bits remain set. Returns
+// the number of bits that were cleared.
+func (b *Bitset32) LimitToHighest(maxBits uint32) uint32 {
+	count := b.Count()
+	if count <= maxBits {
+		return 0
+	}
+	excess := count - maxBits
+	left := excess
+	for i := 0; i < len(b.b) && left > 0; i++ {
+		c := uint32(bits.OnesCount32(b.b[i]))
+		if c <= left {
+			b.b[i] = 0
+			left -= c
+			continue
+		}
+		for ; left > 0; left-- {
+			b.b[i] &= b.b[i] - 1 // clear the lowest set bit
+		}
+	}
+	return excess
+}
+
+// Get the words of the bitset shifted s bits towards the higher indices.
+// Bits shifted past the last word are dropped.
+func (b *Bitset32) shiftedLeft(s uint32) []uint32 {
+	words := make([]uint32, len(b.b))
+	ws, bs := int(s>>slg2_32), s&(sw_32-1)
+	for i := len(words) - 1; i >= ws; i-- {
+		w := b.b[i-ws] << bs
+		if bs > 0 && i-ws > 0 {
+			w |= b.b[i-ws-1] >> (sw_32 - bs)
+		}
+		words[i] = w
+	}
+	return words
+}
+
+// Get the words of the bitset shifted s bits towards the lower indices.
+func (b *Bitset32) shiftedRight(s uint32) []uint32 {
+	words := make([]uint32, len(b.b))
+	ws, bs := int(s>>slg2_32), s&(sw_32-1)
+	for i := 0; i+ws < len(words); i++ {
+		w := b.b[i+ws] >> bs
+		if bs > 0 && i+ws+1 < len(words) {
+			w |= b.b[i+ws+1] << (sw_32 - bs)
+		}
+		words[i] = w
+	}
+	return words
+}
+
+// Get the words of the bitset with each bit ORed with the bits up to radius
+// positions away in the direction shift moves bits from.
+func (b *Bitset32) windowOr(radius uint32, shift func(*Bitset32, uint32) []uint32) []uint32 {
+	t := &Bitset32{n: b.n, b: append([]uint32(nil), b.b...)}
+	l := b.n
+	if radius < l {
+		l = radius + 1
+	}
+	// t covers a window of span bits, so ORing it with itself shifted by
+	// span doubles the window
+	span := uint32(1)
+	for ; span <= l/2; span *= 2 {
+		for i, w := range shift(t, span) {
+			t.b[i] |= w
+		}
+	}
+	if span < l {
+		for i, w := range shift(t, l-span) {
+			t.b[i] |= w
+		}
+	}
+	return t.b
+}
+
+// Get the dilation of the bitset by radius, in which bit i is set if any bit
+// in [i-radius, i+radius] is set in the receiver.
+func (b *Bitset32) Dilate(radius uint32) *Bitset32 {
+	up := b.windowOr(radius, (*Bitset32).shiftedRight)
+	down := b.windowOr(radius, (*Bitset32).shiftedLeft)
+	result := New32(b.n)
+	for i := range result.b {
+		result.b[i] = up[i] | down[i]
+	}
+	result.cleanLastWord()
+	return result
+}
+
+// Get the erosion of the bitset by radius, in which bit i is set if every bit
+// in [i-radius, i+radius] that is within the bitset's length is set in the
+// receiver.
+func (b *Bitset32) Erode(radius uint32) *Bitset32 {
+	return b.Complement().Dilate(radius).Complement()
+}
+
+// Rotate the bitset n bits towards the higher indices, so that bit i moves to
+// (i+n) mod Len(). Bits rotated past the end wrap around to the beginning.
+func (b *Bitset32) RotateLeft(n uint32) {
+	if b.n == 0 {
+		return
+	}
+	n %= b.n
+	if n == 0 {
+		return
+	}
+	hi := b.shiftedLeft(n)
+	lo := b.shiftedRight(b.n - n)
+	for i := range b.b {
+		b.b[i] = hi[i] | lo[i]
+	}
+	b.cleanLastWord()
+}
+
+// Rotate the bitset n bits towards the lower indices, so that bit i moves to
+// (i-n) mod Len(). Bits rotated past the beginning wrap around to the end.
+func (b *Bitset32) RotateRight(n uint32) {
+	if b.n == 0 {
+		return
+	}
+	b.RotateLeft(b.n - n%b.n)
+}
+
+// Returns true if an odd number of bits in the bitset are set.
+func (b *Bitset32) Parity() bool {
+	if len(b.b) == 0 {
+		return false
+	}
+	last := len(b.b) - 1
+	x := b.b[last] & b.lastWordMask()
+	for _, w := range b.b[:last] {
+		x ^= w
+	}
+	for s := sw_32 >> 1; s > 0; s >>= 1 {
+		x ^= x >> s
+	}
+	return x&1 == 1
+}
+
+// Get word i of the bitset, or 0 if the bitset has no such word.
+func (b *Bitset32) word(i uint32) uint32 {
+	if i >= uint32(len(b.b)) {
+		return 0
+	}
+	return b.b[i]
+}
+
+// Get a mask of the bits in word i that are within [from, to). The range
+// must not be empty.
+func rangeMask32(i, from, to uint32) uint32 {
+	m := hff_32
+	if i == from>>slg2_32 {
+		m &= hff_32 << (from & (sw_32 - 1))
+	}
+	if i == (to-1)>>slg2_32 {
+		m &= hff_32 >> (sw_32 - 1 - ((to - 1) & (sw_32 - 1)))
+	}
+	return m
+}
+
+// Test if the bits in [from, to) are the same in both bitsets. Bits beyond a
+// bitset's length are considered clear.
+func (b *Bitset32) EqualRange(ob *Bitset32, from, to uint32) bool {
+	if to > b.n && to > ob.n {
+		to = b.n
+		if ob.n > to {
+			to = ob.n
+		}
+	}
+	if from >= to {
+		return true
+	}
+	for i := from >> slg2_32; i <= (to-1)>>slg2_32; i++ {
+		if (b.word(i)^ob.word(i))&rangeMask32(i, from, to) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Increase the length of the bitset by additional bits, all of which are clear.
+func (b *Bitset32) Extend(additional uint32) {
+	if additional > math.MaxUint32-b.n {
+		panic(fmt.Sprintf("Bitset32 of length %d cannot be extended by %d bits.", b.n, additional))
+	}
+	if b.fixed {
+		if additional > 0 {
+			b.overflow(b.n)
+		}
+		return
+	}
+	b.grow(b.n + additional)
+}
+
+// Returns true if any bit in [from, to) is set.
+func (b *Bitset32) anyInRange(from, to uint32) bool {
+	if to > b.n {
+		to = b.n
+	}
+	if from >= to {
+		return false
+	}
+	for i := from >> slg2_32; i <= (to-1)>>slg2_32; i++ {
+		if b.b[i]&rangeMask32(i, from, to) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Get a bitset in which bit j is set if any bit in the block
+// [j*blockSize, (j+1)*blockSize) is set in the receiver.
+func (b *Bitset32) BlockOccupancy(blockSize uint32) *Bitset32 {
+	return b.Downsample(blockSize, true)
+}
+
+// Returns true if every bit in [from, to) is set. The range must be within the
+// bitset's length.
+func (b *Bitset32) allInRange(from, to uint32) bool {
+	if from >= to {
+		return true
+	}
+	for i := from >> slg2_32; i <= (to-1)>>slg2_32; i++ {
+		if m := rangeMask32(i, from, to); b.b[i]&m != m {
+			return false
+		}
+	}
+	return true
+}
+
+// Get a bitset in which bit j reflects the block [j*stride, (j+1)*stride) of
+// the receiver: if anySet is true, bit j is set if any bit in the block is set,
+// otherwise it is set if every bit in the block is set. The last block is
+// shorter than stride if the length isn't a multiple of it.
+func (b *Bitset32) Downsample(stride uint32, anySet bool) *Bitset32 {
+	if stride == 0 {
+		panic("Bitset32 stride must be greater than 0.")
+	}
+	nBlocks := b.n / stride
+	if b.n%stride != 0 {
+		nBlocks++
+	}
+	result := New32(nBlocks)
+	for j := uint32(0); j < nBlocks; j++ {
+		from := j * stride
+		to := b.n
+		if b.n-from > stride {
+			to = from + stride
+		}
+		if anySet && b.anyInRange(from, to) || !anySet && b.allInRange(from, to) {
+			result.Set(j)
+		}
+	}
+	return result
+}
+
+// Get the number of leading bits, starting at index 0, that are the same in
+// both bitsets. Bits beyond a bitset's length are considered clear, so two
+// bitsets that don't differ have a common prefix as long as the longer one.
+func (b *Bitset32) CommonPrefixLength(ob *Bitset32) uint32 {
+	b, ob = sortByLength32(b, ob)
+	for i, w := range ob.b {
+		if x := w ^ b.word(uint32(i)); x != 0 {
+			return uint32(i)<<slg2_32 + uint32(bits.TrailingZeros32(x))
+		}
+	}
+	return ob.n
+}
+
+// Get k distinct set bits chosen uniformly at random using src, or all of the
+// set bits if fewer than k are set. The indices are not in any particular
+// order.
+func (b *Bitset32) Sample(k int, src rand.Source) []uint32 {
+	if k <= 0 {
+		return []uint32{}
+	}
+	r := rand.New(src)
+	result := []uint32{}
+	seen := 0
+	for i, w := range b.b {
+		for w != 0 {
+			idx := uint32(i)<<slg2_32 + uint32(bits.TrailingZeros32(w))
+			w &= w - 1
+			if seen < k {
+				result = append(result, idx)
+			} else if j := r.Intn(seen + 1); j < k {
+				result[j] = idx
+			}
+			seen++
+		}
+	}
+	return result
+}
+
+// Get the number of maximal runs of contiguous set bits in the bitset.
+func (b *Bitset32) RunCount() uint32 {
+	runs := uint32(0)
+	carry := uint32(0)
+	for _, w := range b.b {
+		// a run starts at each set bit whose preceding bit is clear
+		runs += uint32(bits.OnesCount32(w &^ (w<<1 | carry)))
+		carry = w >> (sw_32 - 1)
+	}
+	return runs
+}
+
+// Get a one-line summary of the bitset's length, number of set bits, density,
+// lowest and highest set bits, and number of runs of set bits. The lowest and
+// highest set bits are omitted if no bits are set.
+func (b *Bitset32) Summary() string {
+	var count, runs, carry, min, max uint32
+	for i, w := range b.b {
+		if w == 0 {
+			carry = 0
+			continue
+		}
+		if count == 0 {
+			min = uint32(i)<<slg2_32 + uint32(bits.TrailingZeros32(w))
+		}
+		max = uint32(i)<<slg2_32 + sw_32 - 1 - uint32(bits.LeadingZeros32(w))
+		count += uint32(bits.OnesCount32(w))
+		runs += uint32(bits.OnesCount32(w &^ (w<<1 | carry)))
+		carry = w >> (sw_32 - 1)
+	}
+	if count == 0 {
+		return fmt.Sprintf("len=%d count=0 density=0.0%% runs=0", b.n)
+	}
+	density := 100 * float64(count) / float64(b.n)
+	return fmt.Sprintf("len=%d count=%d density=%.1f%% min=%d max=%d runs=%d", b.n, count, density, min, max, runs)
+}
+
+// Split the bits of the receiver and another set into those only set in the
+// receiver, those only set in ob, and those set in both. onlyA is as long as
+// the receiver, onlyB as long as ob, and both as long as the shorter set.
+func (b *Bitset32) Diff(ob *Bitset32) (onlyA, onlyB, both *Bitset32) {
+	onlyA = New32(b.n)
+	onlyB = New32(ob.n)
+	s, _ := sortByLength32(b, ob)
+	both = New32(s.n)
+	l := len(b.b)
+	if len(ob.b) > l {
+		l = len(ob.b)
+	}
+	for i := 0; i < l; i++ {
+		x, y := b.word(uint32(i)), ob.word(uint32(i))
+		if i < len(onlyA.b) {
+			onlyA.b[i] = x &^ y
+		}
+		if i < len(onlyB.b) {
+			onlyB.b[i] = y &^ x
+		}
+		if i < len(both.b) {
+			both.b[i] = x & y
+		}
+	}
+	return
+}
+
+// Get the number of contiguous set bits starting at index 0.
+func (b *Bitset32) LeadingOnes() uint32 {
+	n := uint32(0)
+	for _, w := range b.b {
+		if w != hff_32 {
+			n += uint32(bits.TrailingZeros32(^w))
+			break
+		}
+		n += sw_32
+	}
+	if n > b.n {
+		n = b.n
+	}
+	return n
+}
+
+// Replace each word of the bitset with f applied to it. Any bits f sets beyond
+// the bitset's length are cleared.
+func (b *Bitset32) MapWords(f func(uint32) uint32) {
+	for i, w := range b.b {
+		b.b[i] = f(w)
+	}
+	b.cleanLastWord()
+}
+
+// Test if the set bits form a single contiguous run, returning its bounds as
+// [from, to) if they do. Returns false if no bits are set.
+func (b *Bitset32) IsContiguous() (from, to uint32, ok bool) {
+	var count uint32
+	for i, w := range b.b {
+		if w == 0 {
+			continue
+		}
+		if count == 0 {
+			from = uint32(i)<<slg2_32 + uint32(bits.TrailingZeros32(w))
+		}
+		to = uint32(i)<<slg2_32 + sw_32 - uint32(bits.LeadingZeros32(w))
+		count += uint32(bits.OnesCount32(w))
+	}
+	if count == 0 || to-from != count {
+		return 0, 0, false
+	}
+	return from, to, true
+}
+
+// Get the number of bits set in the receiver, in ob, and in both.
+func (b *Bitset32) cardinalities(ob *Bitset32) (ca, cb, cab uint32) {
+	nw := len(b.b)
+	if len(ob.b) > nw {
+		nw = len(ob.b)
+	}
+	for i := uint32(0); i < uint32(nw); i++ {
+		w, o := b.word(i), ob.word(i)
+		ca += uint32(bits.OnesCount32(w))
+		cb += uint32(bits.OnesCount32(o))
+		cab += uint32(bits.OnesCount32(w & o))
+	}
+	return
+}
+
+// Get the Sorensen-Dice coefficient of the receiver and another set,
+// 2|A & B| / (|A| + |B|). Returns 0 if neither set has any bits set.
+func (b *Bitset32) Dice(ob *Bitset32) float64 {
+	ca, cb, cab := b.cardinalities(ob)
+	if ca+cb == 0 {
+		return 0
+	}
+	return 2 * float64(cab) / float64(ca+cb)
+}
+
+// Get the Tanimoto coefficient of the receiver and another set,
+// |A & B| / (|A| + |B| - |A & B|). Returns 0 if neither set has any bits set.
+func (b *Bitset32) Tanimoto(ob *Bitset32) float64 {
+	ca, cb, cab := b.cardinalities(ob)
+	if ca+cb == 0 {
+		return 0
+	}
+	return float64(cab) / float64(ca+cb-cab)
+}
+
+// Flip every bit that is set in mask, expanding the bitset if mask is longer.
+// If the bitset is fixed, bits of mask beyond its length are ignored.
+func (b *Bitset32) FlipWith(mask *Bitset32) {
+	b.growFor(mask)
+	for i := range b.b {
+		b.b[i] ^= mask.word(uint32(i))
+	}
+	b.cleanLastWord()
+}
+
+// Returns true if both bitsets have the same length.
+func (b *Bitset32) SameLength(ob *Bitset32) bool {
+	return b.n == ob.n
+}
+
+// Get the number of adjacent pairs of bits within the bitset's length that
+// differ, i.e. the number of transitions from 0 to 1 or 1 to 0.
+func (b *Bitset32) Transitions() uint32 {
+	if b.n < 2 {
+		return 0
+	}
+	count := uint32(0)
+	last := (b.n - 2) >> slg2_32
+	for i := uint32(0); i <= last; i++ {
+		// bit j of x is set if bits j and j+1 differ
+		x := b.b[i] ^ (b.b[i]>>1 | b.word(i+1)<<(sw_32-1))
+		if i == last {
+			x &= rangeMask32(i, 0, b.n-1)
+		}
+		count += uint32(bits.OnesCount32(x))
+	}
+	return count
+}
+
+// Get the number of set bits in each window [i, i+window) for i from 0 to
+// Len()-window. Returns an empty slice if window is 0 or longer than the
+// bitset.
+func (b *Bitset32) WindowCounts(window uint32) []uint32 {
+	if window == 0 || window > b.n {
+		return []uint32{}
+	}
+	counts := make([]uint32, b.n-window+1)
+	c := uint32(0)
+	for i := uint32(0); i < window; i++ {
+		if b.Test(i) {
+			c++
+		}
+	}
+	counts[0] = c
+	for i := window; i < b.n; i++ {
+		if b.Test(i) {
+			c++
+		}
+		if b.Test(i - window) {
+			c--
+		}
+		counts[i-window+1] = c
+	}
+	return counts
+}
+
+// Get the indices of all set bits in ascending order, and clear them.
+func (b *Bitset32) Drain() []uint32 {
+	result := make([]uint32, 0, b.Count())
+	for i, w := range b.b {
+		for w != 0 {
+			result = append(result, uint32(i)<<slg2_32+uint32(bits.TrailingZeros32(w)))
+			w &= w - 1
+		}
+		b.b[i] = 0
+	}
+	return result
+}
+
+// Get the index of the kth set bit counting down from the highest set bit,
+// starting at 0. Returns false if fewer than k+1 bits are set.
+func (b *Bitset32) SelectFromTop(k uint32) (uint32, bool) {
+	for i := len(b.b) - 1; i >= 0; i-- {
+		w := b.b[i]
+		c := uint32(bits.OnesCount32(w))
+		if k >= c {
+			k -= c
+			continue
+		}
+		for ; k > 0; k-- {
+			w &^= 1 << (sw_32 - 1 - uint32(bits.LeadingZeros32(w)))
+		}
+		return uint32(i)<<slg2_32 + sw_32 - 1 - uint32(bits.LeadingZeros32(w)), true
+	}
+	return 0, false
+}
+
+// Returns true if no bit at or beyond universe is set.
+func (b *Bitset32) FitsWithin(universe uint32) bool {
+	return !b.anyInRange(universe, b.n)
+}
+
+// Bitset &^ (and or) of the receiver and another set, removing only the bits
+// of ob for which pred returns true.
+func (b *Bitset32) DifferenceIf(ob *Bitset32, pred func(i uint32) bool) (result *Bitset32) {
+	result = b.Clone()
+	for i, w := range result.b {
+		// visit each bit set in both sets
+		for x := w & ob.word(uint32(i)); x != 0; x &= x - 1 {
+			j := uint32(bits.TrailingZeros32(x))
+			if pred(uint32(i)<<slg2_32 + j) {
+				result.b[i] &^= 1 << j
+			}
+		}
+	}
+	return
+}
+
+// Get the Hamming distance, the number of bits that differ, between the
+// receiver and each of the given sets.
+func (b *Bitset32) HammingDistances(others []*Bitset32) []uint32 {
+	dist := make([]uint32, len(others))
+	l := len(b.b)
+	for _, o := range others {
+		if len(o.b) > l {
+			l = len(o.b)
+		}
+	}
+	for i := uint32(0); i < uint32(l); i++ {
+		w := b.word(i)
+		for k, o := range others {
+			dist[k] += uint32(bits.OnesCount32(w ^ o.word(i)))
+		}
+	}
+	return dist
+}
+
+// Get a hash of the indices of the set bits that doesn't depend on the order
+// in which they were set. The hash is the sum of a hash of each index, so
+// rather than recomputing it after changing a bit, change the bit with
+// SetRolling or ClearRolling, which update a previously returned hash.
+func (b *Bitset32) RollingHash() uint64 {
+	h := uint64(0)
+	for i, w := range b.b {
+		for ; w != 0; w &= w - 1 {
+			h += hashIndex(uint64(i)<<slg2_32 + uint64(bits.TrailingZeros32(w)))
+		}
+	}
+	return h
+}
+
+// Set bit i to 1, and return h, a hash of the bitset from RollingHash,
+// updated to include bit i.
+func (b *Bitset32) SetRolling(i uint32, h uint64) uint64 {
+	if !b.Test(i) {
+		b.Set(i)
+		if b.Test(i) {
+			h += hashIndex(uint64(i))
+		}
+	}
+	return h
+}
+
+// Set bit i to 0, and return h, a hash of the bitset from RollingHash,
+// updated to exclude bit i.
+func (b *Bitset32) ClearRolling(i uint32, h uint64) uint64 {
+	if b.Test(i) {
+		b.Clear(i)
+		h -= hashIndex(uint64(i))
+	}
+	return h
+}
+
+// Get the number of set bits in each residue class modulo m, i.e. entry r is
+// the number of set bits whose index mod m is r.
+func (b *Bitset32) CountByResidue(m uint32) []uint32 {
+	if m == 0 {
+		panic("Bitset32 residue modulus must be greater than 0.")
+	}
+	counts := make([]uint32, m)
+	for i, w := range b.b {
+		for ; w != 0; w &= w - 1 {
+			counts[(uint32(i)<<slg2_32+uint32(bits.TrailingZeros32(w)))%m]++
+		}
+	}
+	return counts
+}
+
+// Set every bit in [from, to). The range must be within the bitset's length.
+func (b *Bitset32) fillRange(from, to uint32) {
+	if from >= to {
+		return
+	}
+	for i := from >> slg2_32; i <= (to-1)>>slg2_32; i++ {
+		b.b[i] |= rangeMask32(i, from, to)
+	}
+}
+
+// Get a bitset of length Len()*stride in which each set bit i of the receiver
+// is expanded to the block [i*stride, (i+1)*stride).
+func (b *Bitset32) Upsample(stride uint32) *Bitset32 {
+	if stride != 0 && b.n > math.MaxUint32/stride {
+		panic(fmt.Sprintf("Bitset32 of length %d is too long to upsample by %d.", b.n, stride))
+	}
+	result := New32(b.n * stride)
+	for i, w := range b.b {
+		for ; w != 0; w &= w - 1 {
+			from := (uint32(i)<<slg2_32 + uint32(bits.TrailingZeros32(w))) * stride
+			result.fillRange(from, from+stride)
+		}
+	}
+	return result
+}
+
+// Clear every bit that is set in words, a slice of raw words in the same layout
+// as the bitset's own. Words beyond the bitset's length are ignored.
+func (b *Bitset32) AndNotWords(words []uint32) {
+	for i := 0; i < len(b.b) && i < len(words); i++ {
+		b.b[i] &^= words[i]
+	}
+}
+
+// Get the lowest and highest set bits. Returns false if no bits are set.
+func (b *Bitset32) Bounds() (min, max uint32, ok bool) {
+	lo := 0
+	for lo < len(b.b) && b.b[lo] == 0 {
+		lo++
+	}
+	if lo == len(b.b) {
+		return 0, 0, false
+	}
+	hi := len(b.b) - 1
+	for b.b[hi] == 0 {
+		hi--
+	}
+	min = uint32(lo)<<slg2_32 + uint32(bits.TrailingZeros32(b.b[lo]))
+	max = uint32(hi)<<slg2_32 + sw_32 - 1 - uint32(bits.LeadingZeros32(b.b[hi]))
+	return min, max, true
+}
+
+// Get the number of set bits at even indices and at odd indices.
+func (b *Bitset32) CountParity() (even, odd uint32) {
+	last := len(b.b) - 1
+	for i, w := range b.b {
+		if i == last {
+			w &= b.lastWordMask()
+		}
+		even += uint32(bits.OnesCount32(w & m1_32))
+		odd += uint32(bits.OnesCount32(w &^ m1_32))
+	}
+	return
+}
+
+// Set the receiver to the union of a and ob, reusing the receiver's words if
+// it has the capacity to hold the result. The receiver may be a or ob. If the
+// receiver is fixed, it keeps its length and bits beyond it are ignored.
+func (b *Bitset32) UnionWithInto(a, ob *Bitset32) {
+	_, l := sortByLength32(a, ob)
+	n := l.n
+	if b.fixed {
+		b.growFor(a)
+		b.growFor(ob)
+		n = b.n
+	}
+	aw, ow := a.b, ob.b
+	nWords := wordsNeeded32(n)
+	if uint32(cap(b.b)) >= nWords {
+		b.b = b.b[:nWords]
+	} else {
+		b.b = make([]uint32, nWords)
+	}
+	for i := range b.b {
+		w := uint32(0)
+		if i < len(aw) {
+			w = aw[i]
+		}
+		if i < len(ow) {
+			w |= ow[i]
+		}
+		b.b[i] = w
+	}
+	b.n = n
+	b.cleanLastWord()
+}
+
+// Get the index of the only set bit. Returns false if no bits or more than one
+// bit are set.
+func (b *Bitset32) Single() (uint32, bool) {
+	for i, w := range b.b {
+		if w == 0 {
+			continue
+		}
+		if w&(w-1) != 0 {
+			return 0, false
+		}
+		for _, x := range b.b[i+1:] {
+			if x != 0 {
+				return 0, false
+			}
+		}
+		return uint32(i)<<slg2_32 + uint32(bits.TrailingZeros32(w)), true
+	}
+	return 0, false
+}
+
+// Get a bitset of length size in which bit i mod size is set for each set bit
+// i of the receiver.
+func (b *Bitset32) FoldOr(size uint32) *Bitset32 {
+	if size == 0 {
+		panic("Bitset32 fold size must be greater than 0.")
+	}
+	result := New32(size)
+	if size%sw_32 == 0 {
+		// whole words fold onto whole words
+		for i, w := range b.b {
+			result.b[uint32(i)%(size>>slg2_32)] |= w
+		}
+		return result
+	}
+	for i, w := range b.b {
+		for ; w != 0; w &= w - 1 {
+			result.Set((uint32(i)<<slg2_32 + uint32(bits.TrailingZeros32(w))) % size)
+		}
+	}
+	return result
+}
+
+// Get the mean index of the set bits. Returns false if no bits are set.
+func (b *Bitset32) Centroid() (float64, bool) {
+	var sum, count uint64
+	for i, w := range b.b {
+		for ; w != 0; w &= w - 1 {
+			sum += uint64(i)<<slg2_32 + uint64(bits.TrailingZeros32(w))
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return float64(sum) / float64(count), true
+}
+
+// Compare the number of set bits in the receiver and another set. Returns -1
+// if the receiver has fewer bits set, 1 if it has more, and 0 otherwise.
+func (b *Bitset32) CompareCount(ob *Bitset32) int {
+	c, oc := b.Count(), ob.Count()
+	switch {
+	case c < oc:
+		return -1
+	case c > oc:
+		return 1
+	}
+	return 0
+}
+
+// Get a bitset of the same length as the receiver with only the bits that are
+// set in [from, to).
+func (b *Bitset32) IntersectionWithRange(from, to uint32) *Bitset32 {
+	result := New32(b.n)
+	if to > b.n {
+		to = b.n
+	}
+	if from >= to {
+		return result
+	}
+	for i := from >> slg2_32; i <= (to-1)>>slg2_32; i++ {
+		result.b[i] = b.b[i] & rangeMask32(i, from, to)
+	}
+	return result
+}
+
+// Returns true if no bit in [from, to) is set.
+func (b *Bitset32) NoneInRange(from, to uint32) bool {
+	return !b.anyInRange(from, to)
+}
+
+// Get the bits of the bitset packed into bytes, where bit i is bit i%8 of byte
+// i/8 (least significant bit first).
+func (b *Bitset32) ToPackedBytes() []byte {
+	data := make([]byte, (uint64(b.n)+7)/8)
+	wb := int(wb_32)
+	for j := range data {
+		data[j] = byte(b.b[j/wb] >> uint(8*(j%wb)))
+	}
+	return data
+}
+
+// Call f with the index of each clear bit in [from, to), in ascending order,
+// until f returns false. Bits at or beyond the bitset's length are not
+// visited.
+func (b *Bitset32) DoClearRange(from, to uint32, f func(i uint32) bool) {
+	if to > b.n {
+		to = b.n
+	}
+	if from >= to {
+		return
+	}
+	for i := from >> slg2_32; i <= (to-1)>>slg2_32; i++ {
+		for x := ^b.b[i] & rangeMask32(i, from, to); x != 0; x &= x - 1 {
+			if !f(i<<slg2_32 + uint32(bits.TrailingZeros32(x))) {
+				return
+			}
+		}
+	}
+}
+
+// Exchange the bits and length of the receiver and another set without
+// copying any words. Their SetFixed and SetFixedPanics settings and
+// MaxTouched are exchanged along with them.
+func (b *Bitset32) SwapContents(ob *Bitset32) {
+	*b, *ob = *ob, *b
+}
+
+// Get the number of set bits at the indices offset, offset+stride,
+// offset+2*stride and so on.
+func (b *Bitset32) CountStrided(offset, stride uint32) uint32 {
+	if stride == 0 {
+		panic("Bitset32 stride must be greater than 0.")
+	}
+	if offset >= b.n {
+		return 0
+	}
+	count := uint32(0)
+	if sw_32%stride == 0 {
+		// the indices fall at the same positions in every word
+		pattern := uint32(0)
+		for j := offset % stride; j < sw_32; j += stride {
+			pattern |= 1 << j
+		}
+		for i := offset >> slg2_32; i < uint32(len(b.b)); i++ {
+			count += uint32(bits.OnesCount32(b.b[i] & pattern & rangeMask32(i, offset, b.n)))
+		}
+		return count
+	}
+	for i := offset; i < b.n; i += stride {
+		if b.Test(i) {
+			count++
+		}
+		if i > math.MaxUint32-stride {
+			break
+		}
+	}
+	return count
+}
+
+// Get the index of the first set bit at or after i. Returns false if there is
+// no such bit.
+func (b *Bitset32) NextSet(i uint32) (uint32, bool) {
+	if i >= b.n {
+		return 0, false
+	}
+	x := i >> slg2_32
+	w := b.b[x] >> (i & (sw_32 - 1))
+	if w != 0 {
+		return i + uint32(bits.TrailingZeros32(w)), true
+	}
+	for x++; x < uint32(len(b.b)); x++ {
+		if b.b[x] != 0 {
+			return x<<slg2_32 + uint32(bits.TrailingZeros32(b.b[x])), true
+		}
+	}
+	return 0, false
+}
+
+// Expand the bitset to the next power of two bits, if its length isn't one
+// already. The new bits are clear.
+func (b *Bitset32) GrowToPow2() {
+	if b.n&(b.n-1) == 0 && b.n != 0 {
+		return
+	}
+	s := uint(bits.Len32(b.n - 1))
+	if b.n == 0 {
+		s = 0
+	} else if s >= uint(sw_32) {
+		panic(fmt.Sprintf("Bitset32 of length %d cannot grow to a power of two.", b.n))
+	}
+	if b.fixed {
+		b.overflow(b.n)
+		return
+	}
+	b.grow(1 << s)
+}
+
+// Bitset | (or) in place; add the bits of another set to the receiver,
+// expanding it if ob is longer. If the bitset is fixed, bits of ob beyond its
+// length are ignored.
+func (b *Bitset32) UnionWith(ob *Bitset32) {
+	b.growFor(ob)
+	for i := range b.b {
+		b.b[i] |= ob.word(uint32(i))
+	}
+	b.cleanLastWord()
+}
+
+// Add the bits of another set to the receiver, as with UnionWith, unless the
+// two sets have any bit in common, in which case the receiver is left
+// unchanged and an error identifying the first common bit is returned.
+func (b *Bitset32) MergeDisjoint(ob *Bitset32) error {
+	for i, w := range b.b {
+		if x := w & ob.word(uint32(i)); x != 0 {
+			return fmt.Errorf("Bitset32 cannot merge sets that both have bit %d set", uint32(i)<<slg2_32+uint32(bits.TrailingZeros32(x)))
+		}
+	}
+	b.UnionWith(ob)
+	return nil
+}
+
+// Get the number of clear bits between each pair of consecutive set bits, in
+// ascending order. Adjacent set bits have a gap of 0, and the clear bits
+// before the lowest and after the highest set bit are not included, so a set
+// with k bits set has k-1 gaps.
+func (b *Bitset32) Gaps() []uint32 {
+	gaps := []uint32{}
+	prev, ok := b.NextSet(0)
+	for ok {
+		var i uint32
+		if i, ok = b.NextSet(prev + 1); ok {
+			gaps = append(gaps, i-prev-1)
+			prev = i
+		}
+	}
+	return gaps
+}
+
+// Bitset & (and) in place; clear the bits of the receiver that aren't set in
+// another set. The receiver's length is unchanged.
+func (b *Bitset32) IntersectWith(ob *Bitset32) {
+	for i := range b.b {
+		b.b[i] &= ob.word(uint32(i))
+	}
+}
+
+// Bitset &^ (and or) in place; clear the bits of the receiver that are set in
+// another set. The receiver's length is unchanged.
+func (b *Bitset32) DifferenceWith(ob *Bitset32) {
+	for i := range b.b {
+		b.b[i] &^= ob.word(uint32(i))
+	}
+}
+
+// Bitset ^ (xor) in place; flip the bits of the receiver that are set in
+// another set, expanding the receiver if ob is longer. This is the same as
+// FlipWith.
+func (b *Bitset32) SymmetricDifferenceWith(ob *Bitset32) {
+	b.FlipWith(ob)
+}
+
+// Clear every set bit except the n lowest. Returns the number of bits that
+// were cleared. This is the same as LimitTo.
+func (b *Bitset32) KeepLowest(n uint32) uint32 {
+	return b.LimitTo(n)
+}
+
+// Set every bit in [start, end), expanding the bitset if end is beyond its
+// length. If the bitset is fixed, bits beyond its length are not set.
+func (b *Bitset32) SetRange(start, end uint32) {
+	if start >= end {
+		return
+	}
+	if end > b.n {
+		if b.fixed {
+			b.overflow(b.n)
+			end = b.n
+		} else {
+			b.grow(end)
+		}
+	}
+	b.fillRange(start, end)
+}
+
+// Clear every bit in [start, end).
+func (b *Bitset32) ClearRange(start, end uint32) {
+	if end > b.n {
+		end = b.n
+	}
+	if start >= end {
+		return
+	}
+	for i := start >> slg2_32; i <= (end-1)>>slg2_32; i++ {
+		b.b[i] &^= rangeMask32(i, start, end)
+	}
+}
+
+// Call f with each index in [0, universe) whose bit is not set, in ascending
+// order, until f returns false. Indices beyond the bitset's length are clear.
+func (b *Bitset32) ComplementDo(universe uint32, f func(i uint32) bool) {
+	stopped := false
+	b.DoClearRange(0, universe, func(i uint32) bool {
+		stopped = !f(i)
+		return !stopped
+	})
+	if stopped {
+		return
+	}
+	for i := b.n; i < universe; i++ {
+		if !f(i) {
+			return
+		}
+	}
+}
+
+// Flip every bit in [start, end), expanding the bitset if end is beyond its
+// length. If the bitset is fixed, bits beyond its length are not flipped.
+func (b *Bitset32) FlipRange(start, end uint32) {
+	if start >= end {
+		return
+	}
+	if end > b.n {
+		if b.fixed {
+			b.overflow(b.n)
+			end = b.n
+		} else {
+			b.grow(end)
+		}
+	}
+	if start >= end {
+		return
+	}
+	for i := start >> slg2_32; i <= (end-1)>>slg2_32; i++ {
+		b.b[i] ^= rangeMask32(i, start, end)
+	}
+}
+
+// Get the number of set bits in [start, end).
+func (b *Bitset32) CountRange(start, end uint32) uint32 {
+	if end > b.n {
+		end = b.n
+	}
+	if start >= end {
+		return 0
+	}
+	count := uint32(0)
+	for i := start >> slg2_32; i <= (end-1)>>slg2_32; i++ {
+		count += uint32(bits.OnesCount32(b.b[i] & rangeMask32(i, start, end)))
+	}
+	return count
+}
+
+// Get the length of the bitset encoded as a little-endian header, as read by
+// ReadHeader32.
+func (b *Bitset32) Header() []byte {
+	buf := make([]byte, wb_32)
+	binary.LittleEndian.PutUint32(buf, b.n)
+	return buf
+}
+
+// Encode the bitset as its length followed by its words, all little-endian.
+// Implements encoding.BinaryMarshaler.
+func (b *Bitset32) MarshalBinary() ([]byte, error) {
+	return append(b.Header(), b.Bytes()...), nil
+}
+
+// Decode a bitset encoded by MarshalBinary into the receiver, replacing its
+// contents. Implements encoding.BinaryUnmarshaler.
+func (b *Bitset32) UnmarshalBinary(data []byte) error {
+	if err := Verify32(data); err != nil {
+		return err
+	}
+	n, consumed, _ := ReadHeader32(data)
+	data = data[consumed:]
+	wb := int(wb_32)
+	words := make([]uint32, wordsNeeded32(n))
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint32(data[wb*i:])
+	}
+	b.n = n
+	b.b = words
+	b.cleanLastWord()
+	return nil
+}
+
+// Invert every bit in [from, to), expanding the bitset if to is beyond its
+// length. This is the same as FlipRange.
+func (b *Bitset32) NotRange(from, to uint32) {
+	b.FlipRange(from, to)
+}
+
+// Get the smallest period p of the bitset, such that bit i equals bit i+p for
+// every i+p within its length. Returns false if the bitset is of length 0 or
+// has no period of at most half its length.
+func (b *Bitset32) Period() (uint32, bool) {
+	if b.n == 0 {
+		return 0, false
+	}
+	// border[i] is the length of the longest proper prefix of bits [0, i]
+	// that is also a suffix of them (the KMP failure function)
+	border := make([]uint32, b.n)
+	for i := uint32(1); i < b.n; i++ {
+		k := border[i-1]
+		for k > 0 && b.Test(i) != b.Test(k) {
+			k = border[k-1]
+		}
+		if b.Test(i) == b.Test(k) {
+			k++
+		}
+		border[i] = k
+	}
+	p := b.n - border[b.n-1]
+	if p > b.n/2 {
+		return 0, false
+	}
+	return p, true
+}
+
+// Write the bitset to w in the encoding of MarshalBinary, returning the number
+// of bytes written. Implements io.WriterTo.
+func (b *Bitset32) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(b.Header())
+	total := int64(n)
+	if err != nil {
+		return total, err
+	}
+	wb := int(wb_32)
+	nWords := wordsNeeded32(b.n)
+	buf := make([]byte, 0, 512*wb)
+	for i := uint32(0); i < nWords; i++ {
+		buf = buf[:len(buf)+wb]
+		binary.LittleEndian.PutUint32(buf[len(buf)-wb:], b.word(i))
+		if len(buf) == cap(buf) || i == nWords-1 {
+			n, err = w.Write(buf)
+			total += int64(n)
+			if err != nil {
+				return total, err
+			}
+			buf = buf[:0]
+		}
+	}
+	return total, nil
+}
+
+// Read a bitset in the encoding of MarshalBinary from r into the receiver,
+// replacing its contents, and returning the number of bytes read. Implements
+// io.ReaderFrom.
+func (b *Bitset32) ReadFrom(r io.Reader) (int64, error) {
+	wb := int(wb_32)
+	buf := make([]byte, 512*wb)
+	n, err := io.ReadFull(r, buf[:wb])
+	total := int64(n)
+	if err != nil {
+		return total, err
+	}
+	l, _, _ := ReadHeader32(buf[:wb])
+	nWords := int(wordsNeeded32(l))
+	// allocate at most 64K words before any are read, and grow them as they
+	// arrive, so that a corrupt length can't allocate much more than the
+	// data actually read
+	initial := nWords
+	if initial > 1<<16 {
+		initial = 1 << 16
+	}
+	words := make([]uint32, 0, initial)
+	for len(words) < nWords {
+		chunk := nWords - len(words)
+		if chunk > len(buf)/wb {
+			chunk = len(buf) / wb
+		}
+		n, err = io.ReadFull(r, buf[:chunk*wb])
+		total += int64(n)
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return total, err
+		}
+		for j := 0; j < chunk; j++ {
+			words = append(words, binary.LittleEndian.Uint32(buf[j*wb:]))
+		}
+	}
+	if cap(words) != nWords {
+		// trim the slack left by growing
+		words = append(make([]uint32, 0, nWords), words...)
+	}
+	b.n = l
+	b.b = words
+	b.cleanLastWord()
+	return total, nil
+}
+
+// Get a vector of length universe in which entry i is 1 if bit i is set, and
+// 0 otherwise.
+func (b *Bitset32) AsCharacteristicVector(universe uint32) []float64 {
+	v := make([]float64, universe)
+	for i, ok := b.NextSet(0); ok && i < universe; i, ok = b.NextSet(i + 1) {
+		v[i] = 1
+	}
+	return v
+}
+
+// Get a vector of length universe in which entry i is 1 if bit i is set, and
+// 0 otherwise.
+func (b *Bitset32) ToFloat32Vector(universe uint32) []float32 {
+	v := make([]float32, universe)
+	for i, ok := b.NextSet(0); ok && i < universe; i, ok = b.NextSet(i + 1) {
+		v[i] = 1
+	}
+	return v
+}
+
+// The JSON encoding of a Bitset32: its length and its little-endian words,
+// which encoding/json encodes in base64.
+type jsonBitset32 struct {
+	N    uint32 `json:"n"`
+	Bits []byte `json:"bits"`
+}
+
+// Encode the bitset as a JSON object holding its length and its words in
+// base64. Implements json.Marshaler.
+func (b *Bitset32) MarshalJSON() ([]byte, error) {
+	data, err := b.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonBitset32{b.n, data[wb_32:]})
+}
+
+// Decode a bitset encoded by MarshalJSON into the receiver, replacing its
+// contents. A JSON null leaves the receiver unchanged. Implements
+// json.Unmarshaler.
+func (b *Bitset32) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var j jsonBitset32
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	buf := make([]byte, wb_32, int(wb_32)+len(j.Bits))
+	binary.LittleEndian.PutUint32(buf, j.N)
+	return b.UnmarshalBinary(append(buf, j.Bits...))
+}
+
+// Get the bounds of block number block of blockSize bits, clamped to the
+// bitset's length.
+func (b *Bitset32) blockRange(block, blockSize uint32) (from, to uint32) {
+	if blockSize == 0 {
+		panic("Bitset32 block size must be greater than 0.")
+	}
+	if block > b.n/blockSize {
+		return b.n, b.n
+	}
+	from = block * blockSize
+	to = b.n
+	if b.n-from > blockSize {
+		to = from + blockSize
+	}
+	return
+}
+
+// Returns true if any bit in block number block, covering
+// [block*blockSize, (block+1)*blockSize), is set.
+func (b *Bitset32) TestBlock(block, blockSize uint32) bool {
+	return b.anyInRange(b.blockRange(block, blockSize))
+}
+
+// Encode the bitset in the encoding of MarshalBinary. Implements
+// gob.GobEncoder.
+func (b *Bitset32) GobEncode() ([]byte, error) {
+	return b.MarshalBinary()
+}
+
+// Decode a bitset encoded by GobEncode into the receiver, replacing its
+// contents. Implements gob.GobDecoder.
+func (b *Bitset32) GobDecode(data []byte) error {
+	return b.UnmarshalBinary(data)
+}
+
+// Set every bit in block number block, covering
+// [block*blockSize, (block+1)*blockSize), expanding the bitset if needed.
+func (b *Bitset32) SetBlock(block, blockSize uint32) {
+	if blockSize == 0 {
+		panic("Bitset32 block size must be greater than 0.")
+	}
+	if block >= math.MaxUint32/blockSize {
+		panic(fmt.Sprintf("Bitset32 cannot hold block %d of %d bits.", block, blockSize))
+	}
+	b.SetRange(block*blockSize, (block+1)*blockSize)
+}
+
+// Returns true if every bit in block number block, covering
+// [block*blockSize, (block+1)*blockSize), is set. A block that extends beyond
+// the bitset's length is not full.
+func (b *Bitset32) BlockIsFull(block, blockSize uint32) bool {
+	from, to := b.blockRange(block, blockSize)
+	return to-from == blockSize && b.allInRange(from, to)
+}
+
+// Get a copy of the words of the bitset as little-endian bytes. Changes to
+// the returned bytes don't affect the bitset.
+func (b *Bitset32) Bytes() []byte {
+	wb := int(wb_32)
+	nWords := wordsNeeded32(b.n)
+	data := make([]byte, wb*int(nWords))
+	for i := uint32(0); i < nWords; i++ {
+		binary.LittleEndian.PutUint32(data[wb*int(i):], b.word(i))
+	}
+	return data
+}
+
+// Get a table of length Len()+1 in which entry i is the number of set bits in
+// [0, i).
+func (b *Bitset32) PrefixCounts() []uint32 {
+	counts := make([]uint32, uint64(b.n)+1)
+	c := uint32(0)
+	for i := uint32(0); i < b.n; i++ {
+		if i&(sw_32-1) == 0 && b.b[i>>slg2_32] == 0 && b.n-i >= sw_32 {
+			// skip clear words, filling in the running count
+			for j := i + 1; j <= i+sw_32; j++ {
+				counts[j] = c
+			}
+			i += sw_32 - 1
+			continue
+		}
+		c += (b.b[i>>slg2_32] >> (i & (sw_32 - 1))) & 1
+		counts[i+1] = c
+	}
+	return counts
+}
+
+// Get a select table of the bitset: the indices of its set bits in ascending
+// order, so that entry k is the kth set bit. The table is a snapshot, and is
+// not updated when the bitset changes.
+func (b *Bitset32) SelectTable() []uint32 {
+	return b.ToSlice()
+}
+
+// Get the indices of the set bits in ascending order.
+func (b *Bitset32) ToSlice() []uint32 {
+	s := make([]uint32, 0, b.Count())
+	for i, ok := b.NextSet(0); ok; i, ok = b.NextSet(i + 1) {
+		s = append(s, i)
+	}
+	return s
+}
+
+// Call fn with the index of each set bit in ascending order, stopping early
+// if fn returns false.
+func (b *Bitset32) Each(fn func(i uint32) bool) {
+	for x, w := range b.b {
+		for ; w != 0; w &= w - 1 {
+			if !fn(uint32(x)<<slg2_32 + uint32(bits.TrailingZeros32(w))) {
+				return
+			}
+		}
+	}
+}
+
+// Returns true if every set bit of the bitset is also set in words, a raw
+// word slice in the bitset's own layout. Words past the end of the slice are
+// treated as zero.
+func (b *Bitset32) IsSubsetOfWords(words []uint32) bool {
+	for i, w := range b.b {
+		var o uint32
+		if i < len(words) {
+			o = words[i]
+		}
+		if w&^o != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Get the weighted Jaccard similarity of the receiver and another set, the sum
+// of weights[i] over A & B divided by the sum over A | B. Bits with no entry
+// in weights have a weight of 1. Returns 0 if the union has no weight.
+func (b *Bitset32) WeightedJaccard(ob *Bitset32, weights []float64) float64 {
+	nw := len(b.b)
+	if len(ob.b) > nw {
+		nw = len(ob.b)
+	}
+	var inter, union float64
+	for x := 0; x < nw; x++ {
+		bw, ow := b.word(uint32(x)), ob.word(uint32(x))
+		both := bw & ow
+		for w := bw | ow; w != 0; w &= w - 1 {
+			t := uint32(bits.TrailingZeros32(w))
+			weight := 1.0
+			if i := uint32(x)<<slg2_32 + t; i < uint32(len(weights)) {
+				weight = weights[i]
+			}
+			union += weight
+			if both&(1<<t) != 0 {
+				inter += weight
+			}
+		}
+	}
+	if union == 0 {
+		return 0
+	}
+	return inter / union
+}
+
+// Get the number of bits set in both the receiver and another set, without
+// allocating their intersection.
+func (b *Bitset32) IntersectionCount(ob *Bitset32) uint32 {
+	aw, ow := b.b, ob.b
+	if len(ow) < len(aw) {
+		aw, ow = ow, aw
+	}
+	count := uint32(0)
+	for i, w := range aw {
+		count += uint32(bits.OnesCount32(w & ow[i]))
+	}
+	return count
+}
+
+// Get the number of bits set in either the receiver or another set, without
+// allocating their union.
+func (b *Bitset32) UnionCount(ob *Bitset32) uint32 {
+	aw, ow := b.b, ob.b
+	if len(ow) < len(aw) {
+		aw, ow = ow, aw
+	}
+	count := uint32(0)
+	for i, w := range aw {
+		count += uint32(bits.OnesCount32(w | ow[i]))
+	}
+	for _, w := range ow[len(aw):] {
+		count += uint32(bits.OnesCount32(w))
+	}
+	return count
+}
+
+// Write the value of each bit into dst, up to the shorter of len(dst) and the
+// bitset's length. Elements of dst beyond the bitset's length are left as
+// they are.
+func (b *Bitset32) FillBools(dst []bool) {
+	if uint64(len(dst)) > uint64(b.n) {
+		dst = dst[:b.n]
+	}
+	for i := range dst {
+		dst[i] = b.b[uint32(i)>>slg2_32]&(1<<(uint32(i)&(sw_32-1))) != 0
+	}
+}
+
+// Returns true if the receiver and another set have any bit set in common.
+func (b *Bitset32) Intersects(ob *Bitset32) bool {
+	aw, ow := b.b, ob.b
+	if len(ow) < len(aw) {
+		aw, ow = ow, aw
+	}
+	for i, w := range aw {
+		if w&ow[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if every bit set in the receiver is also set in ob.
+func (b *Bitset32) IsSubset(ob *Bitset32) bool {
+	return b.IsSubsetOfWords(ob.b)
+}
+
+// Returns true if every bit set in ob is also set in the receiver.
+func (b *Bitset32) IsSuperset(ob *Bitset32) bool {
+	return ob.IsSubset(b)
+}
+
+// Clear set bits chosen uniformly at random using src until at most
+// targetDensity of the bitset's length is set, returning the number of bits
+// cleared. Panics if targetDensity is not within [0, 1].
+func (b *Bitset32) Thin(targetDensity float64, src rand.Source) uint32 {
+	if !(targetDensity >= 0 && targetDensity <= 1) {
+		panic(fmt.Sprintf("Bitset32 target density %v must be within [0, 1].", targetDensity))
+	}
+	count := b.Count()
+	keep := uint32(targetDensity * float64(b.n))
+	if count <= keep {
+		return 0
+	}
+	// keep each remaining set bit with probability (bits still to keep) /
+	// (set bits still to visit), which keeps exactly keep of them
+	r := rand.New(src)
+	remaining := count
+	for i, w := range b.b {
+		for ; w != 0; w &= w - 1 {
+			if uint32(r.Int63n(int64(remaining))) < keep {
+				keep--
+			} else {
+				b.b[i] &^= w & -w
+			}
+			remaining--
+		}
+	}
+	return count - b.Count()
+}
+
+// Change the length of the bitset to n bits. Growing adds clear bits, and
+// shrinking discards every bit at or beyond n.
+func (b *Bitset32) Resize(n uint32) {
+	if n >= b.n {
+		if n > b.n && b.fixed {
+			b.overflow(b.n)
+			return
+		}
+		b.grow(n)
+		return
+	}
+	b.b = b.b[:wordsNeeded32(n)]
+	b.n = n
+	b.cleanLastWord()
+}
+
+// Append a bit with value v at index Len(), increasing the length by one.
+func (b *Bitset32) Push(v bool) {
+	i := b.n
+	if b.fixed {
+		b.overflow(i)
+		return
+	}
+	b.Extend(1)
+	if v {
+		b.b[i>>slg2_32] |= 1 << (i & (sw_32 - 1))
+	}
+}
+
+// Remove the highest bit, decreasing the length by one, and return its value.
+// The second return value is false if the bitset is empty.
+func (b *Bitset32) Pop() (bool, bool) {
+	if b.n == 0 {
+		return false, false
+	}
+	v := b.Test(b.n - 1)
+	b.Resize(b.n - 1)
+	return v, true
+}
+
+// Shrink the bitset to end just after its highest set bit, or to a length of 0
+// if no bits are set, releasing the words it no longer needs.
+func (b *Bitset32) Compact() {
+	n := uint32(0)
+	for i := len(b.b) - 1; i >= 0; i-- {
+		if b.b[i] != 0 {
+			n = uint32(i)<<slg2_32 + sw_32 - uint32(bits.LeadingZeros32(b.b[i]))
+			break
+		}
+	}
+	nb := make([]uint32, wordsNeeded32(n))
+	copy(nb, b.b)
+	b.b = nb
+	b.n = n
+}
+
+// Make a new bitset with a starting capacity of n bits. The bitset expands
+// automatically.
+func New32(n uint32) *Bitset32 {
+	nWords := wordsNeeded32(n)
+	if nWords > math.MaxInt32-1 {
+		panic(fmt.Sprintf("Bitset32 needs %d %d-bit words to store %d bits, but slices cannot hold more than %d items. Please use a Bitset64 instead.", nWords, sw_32, n, math.MaxInt32-1))
+	}
+	b := &Bitset32{
+		n: n,
+		b: make([]uint32, nWords),
+	}
+	return b
+}
+
+// Interleave the bits of two bitsets, so that bit i of a is at index 2i and
+// bit i of b is at index 2i+1 of the result (a Morton or Z-order encoding).
+// The result is twice as long as the longer of a and b.
+func Interleave32(a, b *Bitset32) *Bitset32 {
+	_, l := sortByLength32(a, b)
+	if l.n > math.MaxUint32/2 {
+		panic(fmt.Sprintf("Bitset32 of length %d is too long to interleave.", l.n))
+	}
+	result := New32(2 * l.n)
+	for k, s := range []*Bitset32{a, b} {
+		for i, w := range s.b {
+			for w != 0 {
+				idx := uint32(i)<<slg2_32 + uint32(bits.TrailingZeros32(w))
+				w &= w - 1
+				result.Set(2*idx + uint32(k))
+			}
+		}
+	}
+	return result
+}
+
+// Split a bitset into the bits at even and odd indices, so that bit 2i is
+// bit i of a and bit 2i+1 is bit i of b. This is the inverse of Interleave32.
+func Deinterleave32(c *Bitset32) (a, b *Bitset32) {
+	a = New32(c.n - c.n/2)
+	b = New32(c.n / 2)
+	for i, w := range c.b {
+		for w != 0 {
+			idx := uint32(i)<<slg2_32 + uint32(bits.TrailingZeros32(w))
+			w &= w - 1
+			if idx&1 == 0 {
+				a.Set(idx / 2)
+			} else {
+				b.Set(idx / 2)
+			}
+		}
+	}
+	return
+}
+
+// Get the bitwise XOR (parity) of any number of bitsets. The result is as long
+// as the longest bitset.
+func XorMany32(sets ...*Bitset32) *Bitset32 {
+	n := uint32(0)
+	for _, s := range sets {
+		if s.n > n {
+			n = s.n
+		}
+	}
+	result := New32(n)
+	for _, s := range sets {
+		for i, w := range s.b {
+			result.b[i] ^= w
+		}
+	}
+	return result
+}
+
+// Encode a slice of bitsets into a single buffer: the number of bitsets,
+// followed by the length and little-endian words of each bitset.
+func MarshalSlice32(sets []*Bitset32) []byte {
+	wb := int(wb_32)
+	size := wb
+	for _, s := range sets {
+		size += wb + wb*int(wordsNeeded32(s.n))
+	}
+	buf := make([]byte, size)
+	binary.LittleEndian.PutUint32(buf, uint32(len(sets)))
+	p := wb
+	for _, s := range sets {
+		binary.LittleEndian.PutUint32(buf[p:], s.n)
+		p += wb
+		for i := uint32(0); i < wordsNeeded32(s.n); i++ {
+			binary.LittleEndian.PutUint32(buf[p:], s.word(i))
+			p += wb
+		}
+	}
+	return buf
+}
+
+// Decode a slice of bitsets encoded by MarshalSlice32.
+func UnmarshalSlice32(data []byte) ([]*Bitset32, error) {
+	wb := int(wb_32)
+	if len(data) < wb {
+		return nil, errors.New("UnmarshalSlice32: data is too short to hold the number of bitsets")
+	}
+	count := binary.LittleEndian.Uint32(data)
+	data = data[wb:]
+	if max := uint32(len(data) / wb); count > max {
+		return nil, fmt.Errorf("UnmarshalSlice32: data is too short to hold %d bitsets", count)
+	}
+	sets := make([]*Bitset32, 0, count)
+	for i := uint32(0); i < count; i++ {
+		if len(data) < wb {
+			return nil, fmt.Errorf("UnmarshalSlice32: bitset %d of %d is truncated", i, count)
+		}
+		n := binary.LittleEndian.Uint32(data)
+		data = data[wb:]
+		if nWords := wordsNeeded32(n); uint64(nWords) > uint64(len(data)/wb) {
+			return nil, fmt.Errorf("UnmarshalSlice32: bitset %d of %d is truncated", i, count)
+		}
+		s := New32(n)
+		for j := range s.b {
+			s.b[j] = binary.LittleEndian.Uint32(data)
+			data = data[wb:]
+		}
+		s.cleanLastWord()
+		sets = append(sets, s)
+	}
+	if len(data) != 0 {
+		return nil, fmt.Errorf("UnmarshalSlice32: %d unexpected trailing bytes", len(data))
+	}
+	return sets, nil
+}
+
+// Make a new bitset of length n*n representing a flattened n by n identity
+// matrix, i.e. with bit i*n+i set for each i < n.
+func New32Diagonal(n uint32) *Bitset32 {
+	if n != 0 && n > math.MaxUint32/n {
+		panic(fmt.Sprintf("Bitset32 cannot hold a %d by %d matrix.", n, n))
+	}
+	b := New32(n * n)
+	for i := uint32(0); i < n; i++ {
+		b.Set(i*n + i)
+	}
+	return b
+}
+
+// A BitWriter32 appends bits to the end of a Bitset32.
+type BitWriter32 struct {
+	b *Bitset32
+}
+
+// Make a new writer that appends to an empty bitset.
+func NewBitWriter32() *BitWriter32 {
+	return &BitWriter32{New32(0)}
+}
+
+// Append a single bit.
+func (w *BitWriter32) WriteBit(v bool) {
+	i := w.b.n
+	w.b.grow(i + 1)
+	if v {
+		w.b.Set(i)
+	}
+}
+
+// Append the count lowest bits of value, starting with the least significant.
+func (w *BitWriter32) WriteBits(value uint64, count int) {
+	if count < 0 || count > 64 {
+		panic(fmt.Sprintf("BitWriter32 cannot write %d bits of a 64-bit value.", count))
+	}
+	i := w.b.n
+	w.b.grow(i + uint32(count))
+	for j := 0; j < count; j++ {
+		if value&(1<<uint(j)) != 0 {
+			w.b.Set(i + uint32(j))
+		}
+	}
+}
+
+// Get the bitset that has been written to.
+func (w *BitWriter32) Bitset() *Bitset32 {
+	return w.b
+}
+
+// A BitReader32 reads the bits of a Bitset32 in order, starting at index 0.
+type BitReader32 struct {
+	b *Bitset32
+	i uint32
+}
+
+// Make a new reader of the bits of b.
+func NewBitReader32(b *Bitset32) *BitReader32 {
+	return &BitReader32{b: b}
+}
+
+// Read the next bit. Returns false if all the bits have been read.
+func (r *BitReader32) ReadBit() (bool, bool) {
+	if r.i >= r.b.n {
+		return false, false
+	}
+	v := r.b.Test(r.i)
+	r.i++
+	return v, true
+}
+
+// Read the next count bits into the lowest bits of a value, starting with the
+// least significant. Returns false, and reads nothing, if fewer than count bits
+// remain.
+func (r *BitReader32) ReadBits(count int) (uint64, bool) {
+	if count < 0 || count > 64 {
+		panic(fmt.Sprintf("BitReader32 cannot read %d bits into a 64-bit value.", count))
+	}
+	if uint64(count) > uint64(r.b.n-r.i) {
+		return 0, false
+	}
+	value := uint64(0)
+	for j := 0; j < count; j++ {
+		if r.b.Test(r.i + uint32(j)) {
+			value |= 1 << uint(j)
+		}
+	}
+	r.i += uint32(count)
+	return value, true
+}
+
+// Make a new bitset from packed bits, where bit i is bit i%8 of byte i/8
+// (least significant bit first). The bitset is of length len(data)*8.
+func New32FromPackedBytes(data []byte) *Bitset32 {
+	if uint64(len(data)) > math.MaxUint32/8 {
+		panic(fmt.Sprintf("Bitset32 cannot hold %d bytes of packed bits.", len(data)))
+	}
+	b := New32(uint32(len(data)) * 8)
+	wb := int(wb_32)
+	for j, v := range data {
+		b.b[j/wb] |= uint32(v) << uint(8*(j%wb))
+	}
+	return b
+}
+
+// Get the number of bit positions that are set in at least k of the given
+// sets. If k is 0 or less, every position within the longest set is counted.
+func CountCommonAtLeast32(sets []*Bitset32, k int) uint32 {
+	var n uint32
+	l := 0
+	for _, s := range sets {
+		if s.n > n {
+			n = s.n
+		}
+		if len(s.b) > l {
+			l = len(s.b)
+		}
+	}
+	if k <= 0 {
+		return n
+	}
+	count := uint32(0)
+	for i := 0; i < l; i++ {
+		var votes [sw_32]int
+		for _, s := range sets {
+			for w := s.word(uint32(i)); w != 0; w &= w - 1 {
+				votes[bits.TrailingZeros32(w)]++
+			}
+		}
+		for _, v := range votes {
+			if v >= k {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// Read a bitset length encoded by Header, returning the length and the number
+// of bytes consumed.
+func ReadHeader32(data []byte) (n uint32, consumed int, err error) {
+	if len(data) < int(wb_32) {
+		return 0, 0, fmt.Errorf("ReadHeader32: need %d bytes, but have %d", wb_32, len(data))
+	}
+	return binary.LittleEndian.Uint32(data), int(wb_32), nil
+}
+
+// Make a new bitset of length n from a copy of words encoded as little-endian
+// bytes, as returned by Bytes. Missing words are clear, and bytes beyond the
+// words needed for n bits are ignored.
+func New32FromBytes(n uint32, data []byte) *Bitset32 {
+	b := New32(n)
+	wb := int(wb_32)
+	for i := range b.b {
+		if len(data) < wb*(i+1) {
+			var w [wb_32]byte
+			copy(w[:], data[wb*i:])
+			b.b[i] = binary.LittleEndian.Uint32(w[:])
+			break
+		}
+		b.b[i] = binary.LittleEndian.Uint32(data[wb*i:])
+	}
+	b.cleanLastWord()
+	return b
+}
+
+// Make a new bitset with the given bits set. The bitset is just long enough to
+// hold the highest index.
+func New32FromIndices(indices ...uint32) *Bitset32 {
+	n := uint32(0)
+	for _, i := range indices {
+		if i >= n {
+			n = i + 1
+		}
+	}
+	b := New32(n)
+	for _, i := range indices {
+		b.Set(i)
+	}
+	return b
+}
+
+// Check that data is a bitset encoded by MarshalBinary, returning nil if
+// UnmarshalBinary would decode it successfully. Nothing is allocated.
+//
+// Only the length header and the number of words are checked: the
+// MarshalBinary encoding has no magic number, version, checksum or width tag,
+// so corrupted words can't be detected, and since a Bitset32 encoding can't
+// be told apart from a Bitset64 one there is a Verify function for each
+// width rather than one for both.
+func Verify32(data []byte) error {
+	n, consumed, err := ReadHeader32(data)
+	if err != nil {
+		return err
+	}
+	size := uint64(wordsNeeded32(n)) * uint64(wb_32)
+	if uint64(len(data)-consumed) != size {
+		return fmt.Errorf("Bitset32 of length %d needs %d bytes of words, but has %d", n, size, len(data)-consumed)
+	}
+	return nil
+}
+
+// Get the matrix of intersection counts of the given sets, where entry [i][j]
+// is the number of bits set in both sets[i] and sets[j]. The diagonal holds
+// each set's count.
+func OverlapMatrix32(sets []*Bitset32) [][]uint32 {
+	m := make([][]uint32, len(sets))
+	cells := make([]uint32, len(sets)*len(sets))
+	for i := range m {
+		m[i] = cells[i*len(sets) : (i+1)*len(sets)]
+	}
+	for i, a := range sets {
+		m[i][i] = a.Count()
+		for j := i + 1; j < len(sets); j++ {
+			c := a.IntersectionCount(sets[j])
+			m[i][j] = c
+			m[j][i] = c
+		}
+	}
+	return m
 }
@@ -1,9 +1,15 @@
 package bitset
 
 import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"io"
 	"math"
 	"math/rand"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestEmpty64(t *testing.T) {
@@ -248,21 +254,2253 @@ func TestSymmetricDifference64(t *testing.T) {
 	}
 }
 
-// func TestComplement64(t *testing.T) {
-// 	a := New64(50)
-// 	b := a.Complement()
-// 	if b.Count() != 50 {
-// 		t.Errorf("Complement failed, size should be 50, but was %d", b.Count())
-// 	}
-// 	a = New64(50)
-// 	a.Set(10)
-// 	a.Set(20)
-// 	a.Set(42)
-// 	b = a.Complement()
-// 	if b.Count() != 47 {
-// 		t.Errorf("Complement failed, size should be 47, but was %d", b.Count())
-// 	}
-// }
+func TestComplement64(t *testing.T) {
+	a := New64(50)
+	b := a.Complement()
+	if b.Count() != 50 {
+		t.Errorf("Complement failed, size should be 50, but was %d", b.Count())
+	}
+	a = New64(50)
+	a.Set(10)
+	a.Set(20)
+	a.Set(42)
+	b = a.Complement()
+	if b.Count() != 47 {
+		t.Errorf("Complement failed, size should be 47, but was %d", b.Count())
+	}
+}
+
+func TestSameBacking64(t *testing.T) {
+	a := New64(100)
+	if !a.SameBacking(a) {
+		t.Error("A bitset should share its backing array with itself")
+	}
+	if a.SameBacking(a.Clone()) {
+		t.Error("A clone should not share its backing array with the original")
+	}
+	c := &Bitset64{n: a.n, b: a.b}
+	if !a.SameBacking(c) {
+		t.Error("Bitsets with the same words should share a backing array")
+	}
+	c.Set(1000)
+	if a.SameBacking(c) {
+		t.Error("A bitset should not share its backing array after expanding")
+	}
+}
+
+func TestLimitTo64(t *testing.T) {
+	a := New64(200)
+	for i := uint64(0); i < 200; i += 3 {
+		a.Set(i)
+	}
+	if c := a.LimitTo(100); c != 0 {
+		t.Errorf("LimitTo above the count should clear nothing, but cleared %d", c)
+	}
+	if c := a.LimitTo(10); c != 57 {
+		t.Errorf("LimitTo should have cleared 57 bits, but cleared %d", c)
+	}
+	if c := a.Count(); c != 10 {
+		t.Errorf("LimitTo should leave 10 bits set, but left %d", c)
+	}
+	if !a.Test(27) || a.Test(30) {
+		t.Error("LimitTo should keep the lowest bits and clear the highest")
+	}
+	if c := a.LimitTo(0); c != 10 || a.Any() {
+		t.Errorf("LimitTo(0) should clear all 10 bits, but cleared %d", c)
+	}
+}
+
+func TestLimitToHighest64(t *testing.T) {
+	a := New64(200)
+	for i := uint64(0); i < 200; i += 3 {
+		a.Set(i)
+	}
+	if c := a.LimitToHighest(10); c != 57 {
+		t.Errorf("LimitToHighest should have cleared 57 bits, but cleared %d", c)
+	}
+	if c := a.Count(); c != 10 {
+		t.Errorf("LimitToHighest should leave 10 bits set, but left %d", c)
+	}
+	if a.Test(168) || !a.Test(171) || !a.Test(198) {
+		t.Error("LimitToHighest should keep the highest bits and clear the lowest")
+	}
+}
+
+func TestDilateErode64(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	for _, n := range []uint64{0, 1, 31, 32, 33, 100, 200} {
+		a := New64(n)
+		for i := uint64(0); i < n; i++ {
+			if r.Intn(6) == 0 {
+				a.Set(i)
+			}
+		}
+		for _, radius := range []uint64{0, 1, 2, 5, 40, 1000} {
+			d := a.Dilate(radius)
+			e := a.Erode(radius)
+			if d.Len() != n || e.Len() != n {
+				t.Fatalf("Dilate and Erode should keep length %d", n)
+			}
+			for i := uint64(0); i < n; i++ {
+				some, every := false, true
+				for j := uint64(0); j < n; j++ {
+					if j+radius >= i && j <= i+radius {
+						some = some || a.Test(j)
+						every = every && a.Test(j)
+					}
+				}
+				if d.Test(i) != some {
+					t.Errorf("Dilate(%d) of length %d should have bit %d %v", radius, n, i, some)
+				}
+				if e.Test(i) != every {
+					t.Errorf("Erode(%d) of length %d should have bit %d %v", radius, n, i, every)
+				}
+			}
+		}
+	}
+}
+
+func TestRotate64(t *testing.T) {
+	for _, n := range []uint64{0, 1, 31, 32, 33, 64, 99, 100, 250} {
+		a := New64(100)
+		for i := uint64(0); i < 100; i += 7 {
+			a.Set(i)
+		}
+		a.Set(99)
+		l := a.Clone()
+		l.RotateLeft(n)
+		r := a.Clone()
+		r.RotateRight(n)
+		for i := uint64(0); i < 100; i++ {
+			if l.Test((i+n)%100) != a.Test(i) {
+				t.Errorf("RotateLeft(%d) should move bit %d to %d", n, i, (i+n)%100)
+			}
+			if r.Test(i) != a.Test((i+n)%100) {
+				t.Errorf("RotateRight(%d) should move bit %d to %d", n, (i+n)%100, i)
+			}
+		}
+		if l.Count() != a.Count() || r.Count() != a.Count() {
+			t.Errorf("Rotating by %d should not change the number of set bits", n)
+		}
+	}
+}
+
+func TestParity64(t *testing.T) {
+	a := New64(100)
+	if a.Parity() {
+		t.Error("An empty set should have even parity")
+	}
+	var z Bitset64
+	if z.Parity() {
+		t.Error("A zero-value set should have even parity")
+	}
+	a.Set(3)
+	a.Set(40)
+	if a.Parity() {
+		t.Error("A set with two bits set should have even parity")
+	}
+	a.Set(99)
+	if !a.Parity() {
+		t.Error("A set with three bits set should have odd parity")
+	}
+	a.Flip(99)
+	a.Set(120)
+	if !a.Parity() {
+		t.Error("A set with three bits set should have odd parity")
+	}
+}
+
+func TestEqualRange64(t *testing.T) {
+	a := New64(100)
+	b := New64(200)
+	for i := uint64(0); i < 100; i += 3 {
+		a.Set(i)
+		b.Set(i)
+	}
+	b.Set(50)
+	b.Set(150)
+	if !a.EqualRange(b, 0, 50) {
+		t.Error("Sets should be equal in [0, 50)")
+	}
+	if a.EqualRange(b, 0, 51) {
+		t.Error("Sets should not be equal in [0, 51)")
+	}
+	if !a.EqualRange(b, 51, 150) {
+		t.Error("Sets should be equal in [51, 150)")
+	}
+	if a.EqualRange(b, 51, 1000) {
+		t.Error("Sets should not be equal in [51, 1000)")
+	}
+	if !a.EqualRange(b, 70, 70) {
+		t.Error("Sets should be equal in an empty range")
+	}
+}
+
+func TestExtend64(t *testing.T) {
+	a := New64(10)
+	a.Set(5)
+	a.Extend(100)
+	if l := a.Len(); l != 110 {
+		t.Errorf("Extended set should be of length 110, not %d", l)
+	}
+	if c := a.Count(); c != 1 || !a.Test(5) {
+		t.Error("Extend should not change which bits are set")
+	}
+	a.Extend(0)
+	if l := a.Len(); l != 110 {
+		t.Errorf("Extending by 0 should not change the length, but it is %d", l)
+	}
+}
+
+func TestBlockOccupancy64(t *testing.T) {
+	a := New64(1000)
+	a.Set(5)
+	a.Set(99)
+	a.Set(100)
+	a.Set(999)
+	o := a.BlockOccupancy(100)
+	if l := o.Len(); l != 10 {
+		t.Errorf("Occupancy of 1000 bits in blocks of 100 should be of length 10, not %d", l)
+	}
+	if c := o.Count(); c != 3 || !o.Test(0) || !o.Test(1) || !o.Test(9) {
+		t.Errorf("Blocks 0, 1 and 9 should be occupied, but got %s", o)
+	}
+	o = a.BlockOccupancy(333)
+	if l := o.Len(); l != 4 {
+		t.Errorf("Occupancy of 1000 bits in blocks of 333 should be of length 4, not %d", l)
+	}
+	if c := o.Count(); c != 2 || !o.Test(0) || !o.Test(3) {
+		t.Errorf("Blocks 0 and 3 should be occupied, but got %s", o)
+	}
+}
+
+func TestCommonPrefixLength64(t *testing.T) {
+	a := New64(100)
+	b := New64(200)
+	if l := a.CommonPrefixLength(b); l != 200 {
+		t.Errorf("Empty sets should have a common prefix of 200, not %d", l)
+	}
+	for i := uint64(0); i < 100; i += 3 {
+		a.Set(i)
+		b.Set(i)
+	}
+	if l := a.CommonPrefixLength(b); l != 200 {
+		t.Errorf("Sets with the same bits should have a common prefix of 200, not %d", l)
+	}
+	b.Set(150)
+	if l := a.CommonPrefixLength(b); l != 150 {
+		t.Errorf("Common prefix should be 150, not %d", l)
+	}
+	a.Set(40)
+	if l := b.CommonPrefixLength(a); l != 40 {
+		t.Errorf("Common prefix should be 40, not %d", l)
+	}
+	a.Clear(0)
+	if l := a.CommonPrefixLength(b); l != 0 {
+		t.Errorf("Common prefix should be 0, not %d", l)
+	}
+}
+
+func TestSample64(t *testing.T) {
+	a := New64(1000)
+	for i := uint64(0); i < 1000; i += 10 {
+		a.Set(i)
+	}
+	s := a.Sample(20, rand.NewSource(0))
+	if len(s) != 20 {
+		t.Fatalf("Sample should return 20 bits, but returned %d", len(s))
+	}
+	seen := New64(1000)
+	for _, i := range s {
+		if !a.Test(i) {
+			t.Errorf("Sampled bit %d is not set", i)
+		}
+		if seen.Test(i) {
+			t.Errorf("Sampled bit %d more than once", i)
+		}
+		seen.Set(i)
+	}
+	if s = a.Sample(500, rand.NewSource(0)); len(s) != 100 {
+		t.Errorf("Sample should return all 100 set bits, but returned %d", len(s))
+	}
+	if s = a.Sample(int(^uint(0)>>1), rand.NewSource(0)); len(s) != 100 {
+		t.Errorf("Sample of the largest int should return all 100 set bits, but returned %d", len(s))
+	}
+	if s = a.Sample(0, rand.NewSource(0)); len(s) != 0 {
+		t.Errorf("Sample of 0 bits should be empty, but returned %d", len(s))
+	}
+}
+
+func TestRunCount64(t *testing.T) {
+	a := New64(200)
+	if r := a.RunCount(); r != 0 {
+		t.Errorf("Empty set should have 0 runs, not %d", r)
+	}
+	for i := uint64(10); i < 20; i++ {
+		a.Set(i)
+	}
+	for i := uint64(60); i < 70; i++ { // crosses a word boundary
+		a.Set(i)
+	}
+	a.Set(0)
+	a.Set(199)
+	if r := a.RunCount(); r != 4 {
+		t.Errorf("Set should have 4 runs, not %d", r)
+	}
+	a.Set(20)
+	a.Set(21)
+	if r := a.RunCount(); r != 4 {
+		t.Errorf("Extending a run should not add a run, but got %d runs", r)
+	}
+}
+
+func TestInterleave64(t *testing.T) {
+	a := New64(100)
+	b := New64(50)
+	a.Set(0)
+	a.Set(99)
+	b.Set(0)
+	b.Set(31)
+	c := Interleave64(a, b)
+	if l := c.Len(); l != 200 {
+		t.Errorf("Interleaved set should be of length 200, not %d", l)
+	}
+	if c.Count() != 4 || !c.Test(0) || !c.Test(198) || !c.Test(1) || !c.Test(63) {
+		t.Errorf("Interleaved set has the wrong bits set: %s", c)
+	}
+	da, db := Deinterleave64(c)
+	if !da.Equal(a) {
+		t.Errorf("Deinterleave should return the original even bits")
+	}
+	if l := db.Len(); l != 100 {
+		t.Errorf("Deinterleaved odd bits should be of length 100, not %d", l)
+	}
+	if db.Count() != 2 || !db.Test(0) || !db.Test(31) {
+		t.Errorf("Deinterleave should return the original odd bits")
+	}
+}
+
+func TestSummary64(t *testing.T) {
+	a := New64(1000)
+	if s := a.Summary(); s != "len=1000 count=0 density=0.0% runs=0" {
+		t.Errorf("Unexpected summary of an empty set: %s", s)
+	}
+	for i := uint64(3); i < 40; i++ {
+		a.Set(i)
+	}
+	a.Set(500)
+	a.Set(998)
+	if s := a.Summary(); s != "len=1000 count=39 density=3.9% min=3 max=998 runs=3" {
+		t.Errorf("Unexpected summary: %s", s)
+	}
+}
+
+func TestXorMany64(t *testing.T) {
+	a := New64(100)
+	b := New64(200)
+	c := New64(50)
+	a.Set(1)
+	a.Set(2)
+	b.Set(2)
+	b.Set(150)
+	c.Set(1)
+	c.Set(2)
+	x := XorMany64(a, b, c)
+	if l := x.Len(); l != 200 {
+		t.Errorf("XorMany should be as long as the longest set, 200, not %d", l)
+	}
+	if !x.Equal(a.SymmetricDifference(b).SymmetricDifference(c)) {
+		t.Errorf("XorMany should equal chained SymmetricDifference, but got %s", x)
+	}
+	if x = XorMany64(); x.Len() != 0 || x.Any() {
+		t.Error("XorMany of no sets should be empty")
+	}
+}
+
+func TestDiff64(t *testing.T) {
+	a := New64(100)
+	b := New64(200)
+	for i := uint64(0); i < 100; i += 2 {
+		a.Set(i)
+	}
+	for i := uint64(0); i < 200; i += 3 {
+		b.Set(i)
+	}
+	onlyA, onlyB, both := a.Diff(b)
+	if !onlyA.Equal(a.Difference(b)) {
+		t.Error("onlyA should equal a.Difference(b)")
+	}
+	if !onlyB.Equal(b.Difference(a)) {
+		t.Error("onlyB should equal b.Difference(a)")
+	}
+	if !both.Equal(a.Intersection(b)) {
+		t.Error("both should equal a.Intersection(b)")
+	}
+}
+
+func TestSetFixed64(t *testing.T) {
+	a := New64(100)
+	a.SetFixed(true)
+	a.Set(99)
+	a.Set(100)
+	a.Flip(1000)
+	if l := a.Len(); l != 100 {
+		t.Errorf("Fixed set should not expand beyond 100, but is of length %d", l)
+	}
+	if c := a.Count(); c != 1 || !a.Test(99) {
+		t.Error("Fixed set should still set bits within its length")
+	}
+	a.Extend(10)
+	a.Push(true)
+	a.GrowToPow2()
+	a.Resize(200)
+	if l := a.Len(); l != 100 {
+		t.Errorf("Fixed set should not be lengthened beyond 100, but is of length %d", l)
+	}
+	a.Resize(50)
+	if l := a.Len(); l != 50 {
+		t.Errorf("Fixed set should still shrink to 50, but is of length %d", l)
+	}
+	a.SetFixedPanics(true)
+	a.Set(49)
+	a.UnionWith(New64(200))
+	for name, f := range map[string]func(){
+		"Set":       func() { a.Set(50) },
+		"Flip":      func() { a.Flip(50) },
+		"SetRange":  func() { a.SetRange(40, 60) },
+		"FlipRange": func() { a.FlipRange(40, 60) },
+		"Extend":    func() { a.Extend(1) },
+		"Push":      func() { a.Push(false) },
+		"Resize":    func() { a.Resize(51) },
+		"UnionWith": func() { a.UnionWith(New64FromIndices(150)) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s beyond the length of a panicking fixed set should panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+	if l := a.Len(); l != 50 {
+		t.Errorf("Panicking fixed set should not expand beyond 50, but is of length %d", l)
+	}
+	a.SetFixedPanics(false)
+	a.SetFixed(false)
+	a.Set(100)
+	if l := a.Len(); l != 101 {
+		t.Errorf("Set should expand after unfixing, but the length is %d", l)
+	}
+}
+
+func TestLeadingOnes64(t *testing.T) {
+	a := New64(100)
+	if l := a.LeadingOnes(); l != 0 {
+		t.Errorf("Empty set should have 0 leading ones, not %d", l)
+	}
+	for i := uint64(0); i < 70; i++ {
+		a.Set(i)
+	}
+	a.Set(80)
+	if l := a.LeadingOnes(); l != 70 {
+		t.Errorf("Set should have 70 leading ones, not %d", l)
+	}
+	for i := uint64(70); i < 100; i++ {
+		a.Set(i)
+	}
+	if l := a.LeadingOnes(); l != 100 {
+		t.Errorf("Full set should have 100 leading ones, not %d", l)
+	}
+	a.Clear(0)
+	if l := a.LeadingOnes(); l != 0 {
+		t.Errorf("Set with bit 0 clear should have 0 leading ones, not %d", l)
+	}
+}
+
+func TestMapWords64(t *testing.T) {
+	a := New64(100)
+	a.Set(3)
+	a.MapWords(func(w uint64) uint64 { return ^w })
+	if c := a.Count(); c != 99 || a.Test(3) {
+		t.Errorf("Inverting every word should leave 99 bits set, but left %d", c)
+	}
+	a = New64(0)
+	a.MapWords(func(w uint64) uint64 { return ^w })
+	if a.Any() {
+		t.Error("Mapping the words of an empty set should not set any bits")
+	}
+	var z Bitset64
+	z.MapWords(func(w uint64) uint64 { return ^w })
+	z.UnionWith(New64(0))
+	if z.Len() != 0 || z.Any() {
+		t.Error("Mapping the words of a zero-value set should leave it empty")
+	}
+}
+
+func TestIsContiguous64(t *testing.T) {
+	a := New64(200)
+	if _, _, ok := a.IsContiguous(); ok {
+		t.Error("Empty set should not be contiguous")
+	}
+	for i := uint64(20); i < 90; i++ {
+		a.Set(i)
+	}
+	if from, to, ok := a.IsContiguous(); !ok || from != 20 || to != 90 {
+		t.Errorf("Set should be contiguous in [20, 90), but got [%d, %d) %v", from, to, ok)
+	}
+	a.Set(91)
+	if _, _, ok := a.IsContiguous(); ok {
+		t.Error("Set with a gap should not be contiguous")
+	}
+}
+
+func TestTanimoto64(t *testing.T) {
+	a := New64(100)
+	b := New64(200)
+	if s := a.Tanimoto(b); s != 0 {
+		t.Errorf("Tanimoto of empty sets should be 0, not %f", s)
+	}
+	for i := uint64(0); i < 40; i++ {
+		a.Set(i)
+	}
+	for i := uint64(20); i < 100; i++ {
+		b.Set(i)
+	}
+	if s := a.Tanimoto(b); s != 0.2 {
+		t.Errorf("Tanimoto should be 20/100, not %f", s)
+	}
+	if s := b.Tanimoto(a); s != 0.2 {
+		t.Errorf("Tanimoto should be symmetric, but got %f", s)
+	}
+}
+
+func TestFlipWith64(t *testing.T) {
+	a := New64(100)
+	m := New64(200)
+	for i := uint64(0); i < 100; i += 2 {
+		a.Set(i)
+	}
+	for i := uint64(0); i < 200; i += 3 {
+		m.Set(i)
+	}
+	x := a.SymmetricDifference(m)
+	a.FlipWith(m)
+	if !a.Equal(x) {
+		t.Error("FlipWith should equal SymmetricDifference")
+	}
+	f := New64(100)
+	f.SetFixed(true)
+	f.FlipWith(m)
+	if l := f.Len(); l != 100 {
+		t.Errorf("FlipWith should not expand a fixed set, but the length is %d", l)
+	}
+	if c := f.Count(); c != 34 {
+		t.Errorf("FlipWith should set 34 bits in a fixed set, but set %d", c)
+	}
+}
+
+func TestMarshalSlice64(t *testing.T) {
+	a := New64(0)
+	b := New64(100)
+	c := New64(1000)
+	b.Set(99)
+	c.Set(0)
+	c.Set(500)
+	data := MarshalSlice64([]*Bitset64{a, b, c})
+	sets, err := UnmarshalSlice64(data)
+	if err != nil {
+		t.Fatalf("UnmarshalSlice failed: %v", err)
+	}
+	if len(sets) != 3 || !sets[0].Equal(a) || !sets[1].Equal(b) || !sets[2].Equal(c) {
+		t.Error("UnmarshalSlice should return the original bitsets")
+	}
+	if sets, err = UnmarshalSlice64(MarshalSlice64(nil)); err != nil || len(sets) != 0 {
+		t.Errorf("Empty slice should round-trip, but got %d bitsets and error %v", len(sets), err)
+	}
+	_, err = UnmarshalSlice64(data[:len(data)-1])
+	if err == nil || !strings.Contains(err.Error(), "bitset 2 of 3") {
+		t.Errorf("Truncated data should fail on bitset 2, but got error %v", err)
+	}
+}
+
+func TestSameLength64(t *testing.T) {
+	a := New64(100)
+	b := New64(100)
+	if !a.SameLength(b) {
+		t.Error("Sets of length 100 should have the same length")
+	}
+	b.Set(100)
+	if a.SameLength(b) {
+		t.Error("Sets of length 100 and 101 should not have the same length")
+	}
+}
+
+func TestTransitions64(t *testing.T) {
+	a := New64(100)
+	if c := a.Transitions(); c != 0 {
+		t.Errorf("Empty set should have 0 transitions, not %d", c)
+	}
+	for i := uint64(30); i < 40; i++ {
+		a.Set(i)
+	}
+	a.Set(0)
+	a.Set(99)
+	if c := a.Transitions(); c != 4 {
+		t.Errorf("Set should have 4 transitions, not %d", c)
+	}
+	b := New64(64)
+	b.Set(63)
+	if c := b.Transitions(); c != 1 {
+		t.Errorf("Set with only its last bit set should have 1 transition, not %d", c)
+	}
+}
+
+func TestNewDiagonal64(t *testing.T) {
+	a := New64Diagonal(10)
+	if l := a.Len(); l != 100 {
+		t.Errorf("Diagonal of 10 should be of length 100, not %d", l)
+	}
+	if c := a.Count(); c != 10 || !a.Test(0) || !a.Test(11) || !a.Test(99) {
+		t.Errorf("Diagonal of 10 should have 10 bits set on the diagonal: %s", a)
+	}
+	if l := New64Diagonal(0).Len(); l != 0 {
+		t.Errorf("Diagonal of 0 should be of length 0, not %d", l)
+	}
+}
+
+func TestWindowCounts64(t *testing.T) {
+	a := New64(100)
+	for i := uint64(0); i < 100; i += 3 {
+		a.Set(i)
+	}
+	a.Set(50)
+	counts := a.WindowCounts(10)
+	if len(counts) != 91 {
+		t.Fatalf("Should have 91 windows of 10 in 100 bits, not %d", len(counts))
+	}
+	for i, c := range counts {
+		want := uint64(0)
+		for j := uint64(i); j < uint64(i)+10; j++ {
+			if a.Test(j) {
+				want++
+			}
+		}
+		if c != want {
+			t.Errorf("Window at %d should have %d bits set, not %d", i, want, c)
+		}
+	}
+	if counts = a.WindowCounts(101); len(counts) != 0 {
+		t.Errorf("Window longer than the set should return no counts, not %d", len(counts))
+	}
+}
+
+func TestDrain64(t *testing.T) {
+	a := New64(100)
+	a.Set(3)
+	a.Set(64)
+	a.Set(99)
+	d := a.Drain()
+	if len(d) != 3 || d[0] != 3 || d[1] != 64 || d[2] != 99 {
+		t.Errorf("Drain should return [3 64 99], not %v", d)
+	}
+	if a.Any() || a.Len() != 100 {
+		t.Error("Drain should clear all bits and keep the length")
+	}
+	if d = a.Drain(); len(d) != 0 {
+		t.Errorf("Draining an empty set should return nothing, not %v", d)
+	}
+}
+
+func TestSelectFromTop64(t *testing.T) {
+	a := New64(200)
+	for i := uint64(0); i < 200; i += 3 {
+		a.Set(i)
+	}
+	for k, want := range []uint64{198, 195, 192} {
+		if i, ok := a.SelectFromTop(uint64(k)); !ok || i != want {
+			t.Errorf("Set bit %d from the top should be %d, not %d", k, want, i)
+		}
+	}
+	if i, ok := a.SelectFromTop(66); !ok || i != 0 {
+		t.Errorf("Set bit 66 from the top should be 0, not %d", i)
+	}
+	if _, ok := a.SelectFromTop(67); ok {
+		t.Error("Set bit 67 from the top should not exist")
+	}
+}
+
+func TestFitsWithin64(t *testing.T) {
+	a := New64(1000)
+	if !a.FitsWithin(0) {
+		t.Error("Empty set should fit within any universe")
+	}
+	a.Set(99)
+	if !a.FitsWithin(100) {
+		t.Error("Set with bit 99 set should fit within 100")
+	}
+	if a.FitsWithin(99) {
+		t.Error("Set with bit 99 set should not fit within 99")
+	}
+	if !a.FitsWithin(5000) {
+		t.Error("Set should fit within a universe longer than itself")
+	}
+}
+
+func TestDifferenceIf64(t *testing.T) {
+	a := New64(100)
+	b := New64(200)
+	for i := uint64(0); i < 100; i++ {
+		a.Set(i)
+	}
+	for i := uint64(0); i < 200; i += 2 {
+		b.Set(i)
+	}
+	c := a.DifferenceIf(b, func(i uint64) bool { return true })
+	if !c.Equal(a.Difference(b)) {
+		t.Error("DifferenceIf with an always-true predicate should equal Difference")
+	}
+	c = a.DifferenceIf(b, func(i uint64) bool { return i < 50 })
+	if n := c.Count(); n != 75 || c.Test(48) || !c.Test(50) {
+		t.Errorf("DifferenceIf should remove only even bits below 50, leaving 75, not %d", n)
+	}
+	if n := a.Count(); n != 100 {
+		t.Errorf("DifferenceIf should not modify the receiver, but it has %d bits set", n)
+	}
+}
+
+func TestHammingDistances64(t *testing.T) {
+	a := New64(100)
+	b := New64(200)
+	c := New64(50)
+	for i := uint64(0); i < 100; i += 2 {
+		a.Set(i)
+	}
+	b.Set(0)
+	b.Set(150)
+	c.Set(1)
+	d := a.HammingDistances([]*Bitset64{b, c, a})
+	if len(d) != 3 {
+		t.Fatalf("Should have 3 distances, not %d", len(d))
+	}
+	for k, o := range []*Bitset64{b, c, a} {
+		if want := a.SymmetricDifference(o).Count(); d[k] != want {
+			t.Errorf("Distance %d should be %d, not %d", k, want, d[k])
+		}
+	}
+}
+
+func TestBitWriter64(t *testing.T) {
+	w := NewBitWriter64()
+	w.WriteBit(true)
+	w.WriteBit(false)
+	w.WriteBits(0x5, 3)
+	w.WriteBits(0, 0)
+	w.WriteBits(1<<63, 64)
+	b := w.Bitset()
+	if l := b.Len(); l != 69 {
+		t.Errorf("Written set should be of length 69, not %d", l)
+	}
+	if c := b.Count(); c != 4 || !b.Test(0) || !b.Test(2) || !b.Test(4) || !b.Test(68) {
+		t.Errorf("Written set has the wrong bits set: %s", b)
+	}
+}
+
+func TestBitReader64(t *testing.T) {
+	w := NewBitWriter64()
+	w.WriteBit(true)
+	w.WriteBits(0x2a, 6)
+	w.WriteBits(1<<63|1, 64)
+	r := NewBitReader64(w.Bitset())
+	if v, ok := r.ReadBit(); !ok || !v {
+		t.Error("First bit should be set")
+	}
+	if v, ok := r.ReadBits(6); !ok || v != 0x2a {
+		t.Errorf("Next 6 bits should be 0x2a, not %#x", v)
+	}
+	if v, ok := r.ReadBits(64); !ok || v != 1<<63|1 {
+		t.Errorf("Next 64 bits should be %#x, not %#x", uint64(1<<63|1), v)
+	}
+	if _, ok := r.ReadBits(1); ok {
+		t.Error("Reading past the end should fail")
+	}
+	if _, ok := r.ReadBit(); ok {
+		t.Error("Reading past the end should fail")
+	}
+}
+
+func TestRollingHash64(t *testing.T) {
+	a := New64(100)
+	b := New64(1000)
+	if a.RollingHash() != b.RollingHash() {
+		t.Error("Empty sets should have the same hash")
+	}
+	for i := uint64(0); i < 100; i += 7 {
+		a.Set(i)
+	}
+	for i := uint64(98); i < 100; i-- {
+		if i%7 == 0 {
+			b.Set(i)
+		}
+	}
+	if a.RollingHash() != b.RollingHash() {
+		t.Error("Sets with the same bits set should have the same hash")
+	}
+	h := b.RollingHash()
+	b.Set(500)
+	if b.RollingHash() != h+hashIndex(500) {
+		t.Error("Setting a bit should add the hash of its index")
+	}
+	h = b.RollingHash()
+	h = b.SetRolling(600, h)
+	h = b.SetRolling(600, h)
+	h = b.ClearRolling(7, h)
+	h = b.ClearRolling(8, h)
+	if b.RollingHash() != h || !b.Test(600) || b.Test(7) {
+		t.Error("A hash updated by SetRolling and ClearRolling should match RollingHash")
+	}
+	b.SetFixed(true)
+	if h = b.SetRolling(5000, h); b.RollingHash() != h {
+		t.Error("SetRolling beyond the length of a fixed set should leave the hash unchanged")
+	}
+}
+
+func TestCountByResidue64(t *testing.T) {
+	a := New64(100)
+	for i := uint64(0); i < 100; i += 4 {
+		a.Set(i)
+	}
+	a.Set(99)
+	counts := a.CountByResidue(4)
+	if len(counts) != 4 || counts[0] != 25 || counts[1] != 0 || counts[2] != 0 || counts[3] != 1 {
+		t.Errorf("Counts by residue mod 4 should be [25 0 0 1], not %v", counts)
+	}
+	counts = a.CountByResidue(1)
+	if len(counts) != 1 || counts[0] != 26 {
+		t.Errorf("Counts by residue mod 1 should be [26], not %v", counts)
+	}
+}
+
+func TestDownsample64(t *testing.T) {
+	a := New64(1000)
+	for i := uint64(100); i < 300; i++ {
+		a.Set(i)
+	}
+	a.Set(450)
+	for i := uint64(900); i < 1000; i++ {
+		a.Set(i)
+	}
+	d := a.Downsample(100, true)
+	if l := d.Len(); l != 10 {
+		t.Errorf("Downsampled set should be of length 10, not %d", l)
+	}
+	if c := d.Count(); c != 4 || !d.Test(1) || !d.Test(2) || !d.Test(4) || !d.Test(9) {
+		t.Errorf("Blocks 1, 2, 4 and 9 should have bits set, but got %s", d)
+	}
+	d = a.Downsample(100, false)
+	if c := d.Count(); c != 3 || !d.Test(1) || !d.Test(2) || !d.Test(9) {
+		t.Errorf("Blocks 1, 2 and 9 should be full, but got %s", d)
+	}
+	d = a.Downsample(300, false)
+	if l := d.Len(); l != 4 {
+		t.Errorf("Downsampled set should be of length 4, not %d", l)
+	}
+	if c := d.Count(); c != 1 || !d.Test(3) {
+		t.Errorf("Only the short last block should be full, but got %s", d)
+	}
+}
+
+func TestUpsample64(t *testing.T) {
+	a := New64(10)
+	a.Set(0)
+	a.Set(3)
+	a.Set(9)
+	u := a.Upsample(50)
+	if l := u.Len(); l != 500 {
+		t.Errorf("Upsampled set should be of length 500, not %d", l)
+	}
+	for i := uint64(0); i < 500; i++ {
+		if u.Test(i) != a.Test(i/50) {
+			t.Errorf("Bit %d of the upsampled set should match bit %d", i, i/50)
+		}
+	}
+	if !u.Downsample(50, false).Equal(a) {
+		t.Error("Downsampling an upsampled set should return the original")
+	}
+	if l := a.Upsample(0).Len(); l != 0 {
+		t.Errorf("Upsampling by 0 should return an empty set, not length %d", l)
+	}
+}
+
+func TestAndNotWords64(t *testing.T) {
+	a := New64(100)
+	for i := uint64(0); i < 100; i++ {
+		a.Set(i)
+	}
+	a.AndNotWords([]uint64{0x1, 0xffffffff << 32})
+	if c := a.Count(); c != 95 || a.Test(0) || a.Test(96) || !a.Test(95) {
+		t.Errorf("AndNotWords should clear bit 0 and bits 96 to 99, leaving 95, not %d", c)
+	}
+	a.AndNotWords(nil)
+	if c := a.Count(); c != 95 {
+		t.Errorf("AndNotWords with no words should not clear any bits, but %d remain", c)
+	}
+}
+
+func TestBounds64(t *testing.T) {
+	a := New64(200)
+	if _, _, ok := a.Bounds(); ok {
+		t.Error("Empty set should have no bounds")
+	}
+	a.Set(70)
+	if min, max, ok := a.Bounds(); !ok || min != 70 || max != 70 {
+		t.Errorf("Bounds should be 70 and 70, not %d and %d", min, max)
+	}
+	a.Set(3)
+	a.Set(199)
+	if min, max, ok := a.Bounds(); !ok || min != 3 || max != 199 {
+		t.Errorf("Bounds should be 3 and 199, not %d and %d", min, max)
+	}
+}
+
+func TestIsFull64(t *testing.T) {
+	if !New64(0).IsFull() {
+		t.Error("Empty set of length 0 should be full")
+	}
+	var z Bitset64
+	if !z.IsFull() || !z.All() {
+		t.Error("Zero-value set should be full")
+	}
+	a := New64(100)
+	if a.IsFull() {
+		t.Error("Empty set of length 100 should not be full")
+	}
+	for i := uint64(0); i < 100; i++ {
+		a.Set(i)
+	}
+	if !a.IsFull() || !a.All() {
+		t.Error("Set with all 100 bits set should be full")
+	}
+	a.Clear(40)
+	if a.IsFull() || a.All() {
+		t.Error("Set with a clear bit should not be full")
+	}
+}
+
+func TestCountParity64(t *testing.T) {
+	a := New64(100)
+	for i := uint64(0); i < 100; i += 3 {
+		a.Set(i)
+	}
+	if even, odd := a.CountParity(); even != 17 || odd != 17 {
+		t.Errorf("Set should have 17 even and 17 odd bits set, not %d and %d", even, odd)
+	}
+	a.Set(97)
+	if even, odd := a.CountParity(); even != 17 || odd != 18 {
+		t.Errorf("Set should have 17 even and 18 odd bits set, not %d and %d", even, odd)
+	}
+}
+
+func TestUnionWithInto64(t *testing.T) {
+	a := New64(100)
+	b := New64(200)
+	for i := uint64(0); i < 100; i += 2 {
+		a.Set(i)
+	}
+	for i := uint64(0); i < 200; i += 3 {
+		b.Set(i)
+	}
+	d := New64(1000)
+	d.Set(999)
+	e := &Bitset64{n: d.n, b: d.b}
+	d.UnionWithInto(a, b)
+	if !d.Equal(a.Union(b)) {
+		t.Error("UnionWithInto should equal Union")
+	}
+	if &d.b[0] != &e.b[0] {
+		t.Error("UnionWithInto should reuse the receiver's words")
+	}
+	d = New64(0)
+	d.UnionWithInto(b, a)
+	if !d.Equal(a.Union(b)) {
+		t.Error("UnionWithInto into a short set should equal Union")
+	}
+	u := a.Union(b)
+	a.UnionWithInto(a, b)
+	if !a.Equal(u) {
+		t.Error("UnionWithInto into one of its operands should equal Union")
+	}
+	f := New64(10)
+	f.SetFixed(true)
+	f.UnionWithInto(f, b)
+	if l := f.Len(); l != 10 {
+		t.Errorf("UnionWithInto should not expand a fixed set, but the length is %d", l)
+	}
+	for i := uint64(0); i < 10; i++ {
+		if f.Test(i) != b.Test(i) {
+			t.Errorf("UnionWithInto into a fixed set should have bit %d %v", i, b.Test(i))
+		}
+	}
+}
+
+func TestSingle64(t *testing.T) {
+	a := New64(200)
+	if _, ok := a.Single(); ok {
+		t.Error("Empty set should not have a single set bit")
+	}
+	a.Set(150)
+	if i, ok := a.Single(); !ok || i != 150 {
+		t.Errorf("Single set bit should be 150, not %d", i)
+	}
+	a.Set(151)
+	if _, ok := a.Single(); ok {
+		t.Error("Set with two bits set in the same word should not have a single set bit")
+	}
+	a.Clear(151)
+	a.Set(2)
+	if _, ok := a.Single(); ok {
+		t.Error("Set with two bits set should not have a single set bit")
+	}
+}
+
+func TestFoldOr64(t *testing.T) {
+	a := New64(1000)
+	a.Set(3)
+	a.Set(131)
+	a.Set(300)
+	a.Set(999)
+	for _, size := range []uint64{128, 100, 7} {
+		f := a.FoldOr(size)
+		if l := f.Len(); l != size {
+			t.Errorf("Folded set should be of length %d, not %d", size, l)
+		}
+		want := New64(size)
+		for i := uint64(0); i < 1000; i++ {
+			if a.Test(i) {
+				want.Set(i % size)
+			}
+		}
+		if !f.Equal(want) {
+			t.Errorf("Fold into %d bits should be %s, not %s", size, want, f)
+		}
+	}
+}
+
+func TestCentroid64(t *testing.T) {
+	a := New64(200)
+	if _, ok := a.Centroid(); ok {
+		t.Error("Empty set should not have a centroid")
+	}
+	a.Set(10)
+	a.Set(20)
+	a.Set(150)
+	if c, ok := a.Centroid(); !ok || c != 60 {
+		t.Errorf("Centroid should be 60, not %f", c)
+	}
+}
+
+func TestCentroidLarge64(t *testing.T) {
+	// 8192 set bits from index 2^58 have indices that sum to more than 2^64,
+	// so check the words such a set would have without allocating all of it
+	words := make([]uint64, 128)
+	for i := range words {
+		words[i] = hff_64
+	}
+	first := uint64(1) << 52
+	want := float64(first<<slg2_64) + float64(8192-1)/2
+	if c, ok := centroidOfWords64(words, first); !ok || math.Abs(c-want) > want*1e-15 {
+		t.Errorf("Centroid should be %f, not %f", want, c)
+	}
+	if _, ok := centroidOfWords64(make([]uint64, 4), first); ok {
+		t.Error("Words with no bits set should not have a centroid")
+	}
+}
+
+func TestCompareCount64(t *testing.T) {
+	a := New64(100)
+	b := New64(1000)
+	if c := a.CompareCount(b); c != 0 {
+		t.Errorf("Empty sets should compare equal, not %d", c)
+	}
+	a.Set(1)
+	if c := a.CompareCount(b); c != 1 {
+		t.Errorf("Set with more bits set should compare 1, not %d", c)
+	}
+	b.Set(500)
+	b.Set(501)
+	if c := a.CompareCount(b); c != -1 {
+		t.Errorf("Set with fewer bits set should compare -1, not %d", c)
+	}
+}
+
+func TestSetTracking64(t *testing.T) {
+	a := New64(100)
+	if m := a.MaxTouched(); m != 0 {
+		t.Errorf("Untouched set should have a high-water mark of 0, not %d", m)
+	}
+	a.SetTracking(50)
+	a.SetTracking(500)
+	a.SetTracking(10)
+	a.Clear(500)
+	if !a.Test(10) || !a.Test(50) {
+		t.Error("SetTracking should set bits")
+	}
+	if m := a.MaxTouched(); m != 500 {
+		t.Errorf("High-water mark should be 500, not %d", m)
+	}
+}
+
+func TestIntersectionWithRange64(t *testing.T) {
+	a := New64(200)
+	for i := uint64(0); i < 200; i += 3 {
+		a.Set(i)
+	}
+	r := a.IntersectionWithRange(30, 100)
+	if l := r.Len(); l != 200 {
+		t.Errorf("Result should be of length 200, not %d", l)
+	}
+	for i := uint64(0); i < 200; i++ {
+		if want := a.Test(i) && i >= 30 && i < 100; r.Test(i) != want {
+			t.Errorf("Bit %d should be %v", i, want)
+		}
+	}
+	if r = a.IntersectionWithRange(150, 1000); r.Count() != 17 {
+		t.Errorf("Range beyond the length should keep 17 bits, not %d", r.Count())
+	}
+	if r = a.IntersectionWithRange(100, 30); r.Any() {
+		t.Error("Empty range should keep no bits")
+	}
+}
+
+func TestNoneInRange64(t *testing.T) {
+	a := New64(200)
+	a.Set(50)
+	a.Set(150)
+	if !a.NoneInRange(51, 150) {
+		t.Error("No bits should be set in [51, 150)")
+	}
+	if a.NoneInRange(0, 51) || a.NoneInRange(150, 151) {
+		t.Error("Bits should be set in [0, 51) and [150, 151)")
+	}
+	if !a.NoneInRange(151, 1000) || !a.NoneInRange(60, 60) {
+		t.Error("No bits should be set beyond the highest set bit or in an empty range")
+	}
+}
+
+func TestPackedBytes64(t *testing.T) {
+	data := []byte{0x01, 0x80, 0x00, 0xff, 0x10}
+	a := New64FromPackedBytes(data)
+	if l := a.Len(); l != 40 {
+		t.Errorf("Set should be of length 40, not %d", l)
+	}
+	if c := a.Count(); c != 11 || !a.Test(0) || !a.Test(15) || !a.Test(24) || !a.Test(36) {
+		t.Errorf("Set has the wrong bits set: %s", a)
+	}
+	if p := a.ToPackedBytes(); !bytes.Equal(p, data) {
+		t.Errorf("ToPackedBytes should return %v, not %v", data, p)
+	}
+	b := New64(10)
+	b.Set(9)
+	if p := b.ToPackedBytes(); !bytes.Equal(p, []byte{0x00, 0x02}) {
+		t.Errorf("ToPackedBytes of 10 bits should return [0 2], not %v", p)
+	}
+	if l := New64FromPackedBytes(nil).Len(); l != 0 {
+		t.Errorf("Set from no bytes should be of length 0, not %d", l)
+	}
+}
+
+func TestDoClearRange64(t *testing.T) {
+	a := New64(200)
+	for i := uint64(0); i < 200; i++ {
+		if i%10 != 0 {
+			a.Set(i)
+		}
+	}
+	var got []uint64
+	a.DoClearRange(15, 1000, func(i uint64) bool {
+		got = append(got, i)
+		return true
+	})
+	if len(got) != 18 || got[0] != 20 || got[17] != 190 {
+		t.Errorf("Clear bits in [15, 200) should be 20 to 190, not %v", got)
+	}
+	got = got[:0]
+	a.DoClearRange(0, 200, func(i uint64) bool {
+		got = append(got, i)
+		return len(got) < 3
+	})
+	if len(got) != 3 || got[2] != 20 {
+		t.Errorf("Iteration should stop after 3 clear bits, but visited %v", got)
+	}
+}
+
+func TestFlipBeyondLength64(t *testing.T) {
+	a := New64(10)
+	a.Flip(100)
+	if !a.Test(100) {
+		t.Error("Flipping a bit beyond the length should set it")
+	}
+	if l := a.Len(); l != 101 {
+		t.Errorf("Flipping bit 100 should expand the set to 101, not %d", l)
+	}
+	a.Flip(100)
+	if a.Test(100) {
+		t.Error("Flipping a set bit should clear it")
+	}
+}
+
+func TestSwapContents64(t *testing.T) {
+	a := New64(100)
+	b := New64(1000)
+	a.Set(5)
+	b.SetTracking(999)
+	a.SetFixed(true)
+	ac, bc := a.Clone(), b.Clone()
+	a.SwapContents(b)
+	if !a.Equal(bc) || !b.Equal(ac) {
+		t.Error("SwapContents should exchange the contents of the sets")
+	}
+	if a.MaxTouched() != 999 || b.MaxTouched() != 0 {
+		t.Error("SwapContents should exchange MaxTouched")
+	}
+	a.Set(1000)
+	b.Set(100)
+	if a.Len() != 1001 || b.Len() != 100 {
+		t.Error("SwapContents should exchange the fixed setting")
+	}
+}
+
+func TestCountStrided64(t *testing.T) {
+	a := New64(200)
+	for i := uint64(0); i < 200; i += 3 {
+		a.Set(i)
+	}
+	for _, c := range []struct{ offset, stride uint64 }{{0, 1}, {1, 4}, {5, 8}, {0, 3}, {7, 10}, {190, 64}, {300, 2}} {
+		want := uint64(0)
+		for i := c.offset; i < 200; i += c.stride {
+			if a.Test(i) {
+				want++
+			}
+		}
+		if got := a.CountStrided(c.offset, c.stride); got != want {
+			t.Errorf("CountStrided(%d, %d) should be %d, not %d", c.offset, c.stride, want, got)
+		}
+	}
+}
+
+func TestNextSet64(t *testing.T) {
+	a := New64(1000)
+	if _, ok := a.NextSet(0); ok {
+		t.Error("Empty set should have no next set bit")
+	}
+	want := []uint64{0, 31, 32, 500, 999}
+	for _, i := range want {
+		a.Set(i)
+	}
+	var got []uint64
+	for i, ok := a.NextSet(0); ok; i, ok = a.NextSet(i + 1) {
+		got = append(got, i)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("NextSet should visit %v, not %v", want, got)
+	}
+	for k := range want {
+		if got[k] != want[k] {
+			t.Errorf("NextSet should visit %v, not %v", want, got)
+		}
+	}
+	if i, ok := a.NextSet(33); !ok || i != 500 {
+		t.Errorf("Next set bit after 33 should be 500, not %d", i)
+	}
+	if _, ok := a.NextSet(1000); ok {
+		t.Error("There should be no set bit beyond the length")
+	}
+}
+
+func TestDice64(t *testing.T) {
+	a := New64(100)
+	b := New64(200)
+	if s := a.Dice(b); s != 0 {
+		t.Errorf("Dice of empty sets should be 0, not %f", s)
+	}
+	for i := uint64(0); i < 40; i++ {
+		a.Set(i)
+	}
+	for i := uint64(20); i < 80; i++ {
+		b.Set(i)
+	}
+	if s := a.Dice(b); s != 0.4 {
+		t.Errorf("Dice should be 40/100, not %f", s)
+	}
+	if s := b.Dice(a); s != 0.4 {
+		t.Errorf("Dice should be symmetric, but got %f", s)
+	}
+}
+
+func TestGrowToPow264(t *testing.T) {
+	for _, c := range []struct{ n, want uint64 }{{0, 1}, {1, 1}, {2, 2}, {3, 4}, {64, 64}, {100, 128}, {1025, 2048}} {
+		a := New64(c.n)
+		if c.n > 0 {
+			a.Set(c.n - 1)
+		}
+		a.GrowToPow2()
+		if l := a.Len(); l != c.want {
+			t.Errorf("Set of length %d should grow to %d, not %d", c.n, c.want, l)
+		}
+		if c.n > 0 && (a.Count() != 1 || !a.Test(c.n-1)) {
+			t.Errorf("Growing a set of length %d should not change its bits", c.n)
+		}
+	}
+}
+
+func TestUnionWith64(t *testing.T) {
+	sets := []*Bitset64{New64(100), New64(300), New64(50), New64(200)}
+	for k, s := range sets {
+		for i := uint64(k); i < s.Len(); i += uint64(k) + 2 {
+			s.Set(i)
+		}
+	}
+	a := New64(0)
+	u := New64(0)
+	for _, s := range sets {
+		a.UnionWith(s)
+		u = u.Union(s)
+	}
+	if !a.Equal(u) {
+		t.Error("Repeated UnionWith should equal chained Union")
+	}
+	if l := a.Len(); l != 300 {
+		t.Errorf("UnionWith should expand the set to 300, not %d", l)
+	}
+}
+
+func TestMergeDisjoint64(t *testing.T) {
+	a := New64(100)
+	b := New64(200)
+	a.Set(10)
+	b.Set(20)
+	b.Set(150)
+	if err := a.MergeDisjoint(b); err != nil {
+		t.Errorf("Merging disjoint sets failed: %v", err)
+	}
+	if a.Count() != 3 || a.Len() != 200 {
+		t.Errorf("Merged set should have 3 bits set and be of length 200: %s", a)
+	}
+	c := New64(50)
+	c.Set(20)
+	c.Set(30)
+	if err := c.MergeDisjoint(a); err == nil || !strings.Contains(err.Error(), "bit 20") {
+		t.Errorf("Merging overlapping sets should fail on bit 20, but got error %v", err)
+	}
+	if c.Count() != 2 || c.Len() != 50 {
+		t.Error("A failed merge should not modify the receiver")
+	}
+}
+
+func TestGaps64(t *testing.T) {
+	a := New64(200)
+	if g := a.Gaps(); len(g) != 0 {
+		t.Errorf("Empty set should have no gaps, not %v", g)
+	}
+	a.Set(5)
+	if g := a.Gaps(); len(g) != 0 {
+		t.Errorf("Set with one bit set should have no gaps, not %v", g)
+	}
+	a.Set(6)
+	a.Set(10)
+	a.Set(199)
+	g := a.Gaps()
+	if len(g) != 3 || g[0] != 0 || g[1] != 3 || g[2] != 188 {
+		t.Errorf("Gaps should be [0 3 188], not %v", g)
+	}
+}
+
+func TestIntersectWith64(t *testing.T) {
+	for _, n := range []uint64{50, 100, 300} {
+		a := New64(n)
+		b := New64(100)
+		for i := uint64(0); i < n; i += 2 {
+			a.Set(i)
+		}
+		for i := uint64(0); i < 100; i += 3 {
+			b.Set(i)
+		}
+		x := a.Intersection(b)
+		a.IntersectWith(b)
+		if l := a.Len(); l != n {
+			t.Errorf("IntersectWith should not change the length %d, but it is %d", n, l)
+		}
+		if a.Count() != x.Count() || !a.EqualRange(x, 0, n) {
+			t.Errorf("IntersectWith on a set of length %d should equal Intersection", n)
+		}
+	}
+}
+
+func TestDifferenceWith64(t *testing.T) {
+	for _, n := range []uint64{50, 100, 300} {
+		a := New64(n)
+		b := New64(100)
+		for i := uint64(0); i < n; i += 2 {
+			a.Set(i)
+		}
+		for i := uint64(0); i < 100; i += 3 {
+			b.Set(i)
+		}
+		d := a.Difference(b)
+		x := a.SymmetricDifference(b)
+		c := a.Clone()
+		a.DifferenceWith(b)
+		if !a.Equal(d) {
+			t.Errorf("DifferenceWith on a set of length %d should equal Difference", n)
+		}
+		c.SymmetricDifferenceWith(b)
+		if !c.Equal(x) {
+			t.Errorf("SymmetricDifferenceWith on a set of length %d should equal SymmetricDifference", n)
+		}
+	}
+}
+
+func TestKeepLowest64(t *testing.T) {
+	a := New64(100)
+	for i := uint64(0); i < 100; i += 5 {
+		a.Set(i)
+	}
+	if c := a.KeepLowest(3); c != 17 {
+		t.Errorf("KeepLowest should have cleared 17 bits, but cleared %d", c)
+	}
+	if c := a.Count(); c != 3 || !a.Test(0) || !a.Test(5) || !a.Test(10) {
+		t.Errorf("KeepLowest should keep bits 0, 5 and 10: %s", a)
+	}
+}
+
+func TestSetRange64(t *testing.T) {
+	for _, c := range []struct{ start, end uint64 }{{3, 20}, {10, 150}, {32, 64}, {40, 40}, {90, 90}} {
+		a := New64(100)
+		a.SetRange(c.start, c.end)
+		b := New64(100)
+		for i := c.start; i < c.end; i++ {
+			b.Set(i)
+		}
+		if !a.Equal(b) {
+			t.Errorf("SetRange(%d, %d) should set the same bits as Set: %s", c.start, c.end, a)
+		}
+		a.SetRange(0, a.Len())
+		a.ClearRange(c.start, c.end)
+		if n := a.Count(); n != a.Len()-(c.end-c.start) || a.Test(c.start) && c.start < c.end {
+			t.Errorf("ClearRange(%d, %d) should clear %d bits", c.start, c.end, c.end-c.start)
+		}
+	}
+	a := New64(10)
+	a.ClearRange(5, 1000)
+	if l := a.Len(); l != 10 {
+		t.Errorf("ClearRange should not expand the set, but the length is %d", l)
+	}
+	a.SetRange(100, 50)
+	a.SetRange(100, 100)
+	if l := a.Len(); l != 10 || a.Count() != 0 {
+		t.Errorf("SetRange of an empty range should not change the set, but the length is %d", l)
+	}
+}
+
+func TestComplementDo64(t *testing.T) {
+	a := New64(100)
+	for i := uint64(0); i < 100; i++ {
+		if i != 7 && i != 64 {
+			a.Set(i)
+		}
+	}
+	var got []uint64
+	a.ComplementDo(103, func(i uint64) bool {
+		got = append(got, i)
+		return true
+	})
+	if len(got) != 5 || got[0] != 7 || got[1] != 64 || got[2] != 100 || got[4] != 102 {
+		t.Errorf("ComplementDo should visit [7 64 100 101 102], not %v", got)
+	}
+	got = got[:0]
+	a.ComplementDo(103, func(i uint64) bool {
+		got = append(got, i)
+		return len(got) < 2
+	})
+	if len(got) != 2 {
+		t.Errorf("ComplementDo should stop after 2 indices, but visited %v", got)
+	}
+}
+
+func TestFlipRange64(t *testing.T) {
+	a := New64(100)
+	for i := uint64(0); i < 100; i += 3 {
+		a.Set(i)
+	}
+	for _, c := range []struct{ start, end uint64 }{{3, 20}, {10, 90}, {32, 64}, {40, 40}} {
+		b := a.Clone()
+		b.FlipRange(c.start, c.end)
+		for i := uint64(0); i < 100; i++ {
+			if want := a.Test(i) != (i >= c.start && i < c.end); b.Test(i) != want {
+				t.Errorf("FlipRange(%d, %d) should leave bit %d %v", c.start, c.end, i, want)
+			}
+		}
+		b.FlipRange(c.start, c.end)
+		if !b.Equal(a) {
+			t.Errorf("Flipping [%d, %d) twice should restore the original set", c.start, c.end)
+		}
+	}
+	a.FlipRange(90, 150)
+	if l := a.Len(); l != 150 {
+		t.Errorf("FlipRange should expand the set to 150, not %d", l)
+	}
+	if !a.Test(149) || !a.Test(100) {
+		t.Error("FlipRange should set bits beyond the original length")
+	}
+	a.FlipRange(200, 200)
+	a.FlipRange(300, 250)
+	if l := a.Len(); l != 150 {
+		t.Errorf("FlipRange of an empty range should not expand the set, but the length is %d", l)
+	}
+}
+
+func TestCountCommonAtLeast64(t *testing.T) {
+	a := New64(100)
+	b := New64(200)
+	c := New64(50)
+	for i := uint64(0); i < 100; i += 2 {
+		a.Set(i)
+	}
+	for i := uint64(0); i < 200; i += 3 {
+		b.Set(i)
+	}
+	for i := uint64(0); i < 50; i += 5 {
+		c.Set(i)
+	}
+	sets := []*Bitset64{a, b, c}
+	for k := 0; k <= 4; k++ {
+		want := uint64(0)
+		for i := uint64(0); i < 200; i++ {
+			votes := 0
+			for _, s := range sets {
+				if s.Test(i) {
+					votes++
+				}
+			}
+			if votes >= k {
+				want++
+			}
+		}
+		if got := CountCommonAtLeast64(sets, k); got != want {
+			t.Errorf("Positions set in at least %d sets should be %d, not %d", k, want, got)
+		}
+	}
+}
+
+func TestCountRange64(t *testing.T) {
+	a := New64(200)
+	for i := uint64(0); i < 200; i += 3 {
+		a.Set(i)
+	}
+	if c := a.CountRange(0, a.Len()); c != a.Count() {
+		t.Errorf("CountRange over the whole set should be %d, not %d", a.Count(), c)
+	}
+	for _, c := range []struct{ start, end uint64 }{{3, 20}, {10, 150}, {32, 64}, {40, 40}, {150, 1000}, {50, 10}} {
+		want := uint64(0)
+		for i := c.start; i < c.end; i++ {
+			if a.Test(i) {
+				want++
+			}
+		}
+		if got := a.CountRange(c.start, c.end); got != want {
+			t.Errorf("CountRange(%d, %d) should be %d, not %d", c.start, c.end, want, got)
+		}
+	}
+}
+
+func TestHeader64(t *testing.T) {
+	a := New64(1000)
+	data := append(a.Header(), 0xff)
+	n, consumed, err := ReadHeader64(data)
+	if err != nil || n != 1000 || consumed != len(data)-1 {
+		t.Errorf("ReadHeader should return 1000 and %d, not %d and %d (%v)", len(data)-1, n, consumed, err)
+	}
+	if _, _, err = ReadHeader64(data[:2]); err == nil {
+		t.Error("ReadHeader of a short header should fail")
+	}
+}
+
+func TestNotRange64(t *testing.T) {
+	a := New64(100)
+	a.Set(10)
+	a.NotRange(5, 120)
+	if l := a.Len(); l != 120 {
+		t.Errorf("NotRange should expand the set to 120, not %d", l)
+	}
+	if c := a.Count(); c != 114 || a.Test(10) || a.Test(4) || !a.Test(119) {
+		t.Errorf("NotRange should invert bits 5 to 119, leaving 114 set, not %d", c)
+	}
+}
+
+func TestPeriod64(t *testing.T) {
+	if _, ok := New64(0).Period(); ok {
+		t.Error("Set of length 0 should have no period")
+	}
+	if p, ok := New64(100).Period(); !ok || p != 1 {
+		t.Errorf("Clear set should have a period of 1, not %d", p)
+	}
+	a := New64(100)
+	for i := uint64(0); i < 100; i += 7 {
+		a.Set(i)
+		a.Set(i + 2)
+	}
+	if p, ok := a.Period(); !ok || p != 7 {
+		t.Errorf("Set should have a period of 7, not %d", p)
+	}
+	a.Set(50)
+	if _, ok := a.Period(); ok {
+		t.Error("Set with a broken pattern should have no period")
+	}
+}
+
+func TestMarshalBinary64(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for _, n := range []uint64{0, 1, 63, 64, 65, 100, 1000} {
+		a := New64(n)
+		for i := uint64(0); i < n; i++ {
+			if r.Intn(2) == 0 {
+				a.Set(i)
+			}
+		}
+		data, err := a.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary failed: %v", err)
+		}
+		b := New64(5)
+		b.Set(3)
+		if err = b.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary failed: %v", err)
+		}
+		if !b.Equal(a) {
+			t.Errorf("Set of length %d should round-trip, but got %s", n, b)
+		}
+		if err = b.UnmarshalBinary(data[:len(data)-1]); err == nil {
+			t.Errorf("UnmarshalBinary of truncated data of length %d should fail", n)
+		}
+	}
+	var z Bitset64
+	data, _ := z.MarshalBinary()
+	b := New64(5)
+	if err := b.UnmarshalBinary(data); err != nil || b.Len() != 0 {
+		t.Errorf("Zero-value set should round-trip, but got length %d and error %v", b.Len(), err)
+	}
+	var buf bytes.Buffer
+	z.WriteTo(&buf)
+	if _, err := b.ReadFrom(&buf); err != nil || b.Len() != 0 {
+		t.Errorf("Zero-value set should round-trip through WriteTo, but got length %d and error %v", b.Len(), err)
+	}
+	sets, err := UnmarshalSlice64(MarshalSlice64([]*Bitset64{&z}))
+	if err != nil || len(sets) != 1 || sets[0].Len() != 0 {
+		t.Errorf("Slice of a zero-value set should round-trip, but got error %v", err)
+	}
+}
+
+func TestMarshalBinaryHuge64(t *testing.T) {
+	a := New64(math.MaxUint32 + 1)
+	a.Set(math.MaxUint32)
+	n, _, err := ReadHeader64(a.Header())
+	if err != nil || n != math.MaxUint32+1 {
+		t.Errorf("Header should hold a length of %d, not %d (%v)", uint64(math.MaxUint32+1), n, err)
+	}
+	b := New64(0)
+	if err = b.UnmarshalBinary(a.Header()); err == nil {
+		t.Error("UnmarshalBinary of a huge length without its words should fail")
+	}
+}
+
+func TestWriteToReadFrom64(t *testing.T) {
+	for _, n := range []uint64{0, 100, 100000, 1 << 23} {
+		a := New64(n)
+		for i := uint64(0); i < n; i += 7 {
+			a.Set(i)
+		}
+		var buf bytes.Buffer
+		written, err := a.WriteTo(&buf)
+		if err != nil {
+			t.Fatalf("WriteTo failed: %v", err)
+		}
+		data, _ := a.MarshalBinary()
+		if written != int64(len(data)) || !bytes.Equal(buf.Bytes(), data) {
+			t.Errorf("WriteTo should write the same %d bytes as MarshalBinary, but wrote %d", len(data), written)
+		}
+		for _, r := range []io.Reader{bytes.NewReader(data), iotest.OneByteReader(bytes.NewReader(data))} {
+			b := New64(0)
+			read, err := b.ReadFrom(r)
+			if err != nil || read != written {
+				t.Errorf("ReadFrom should read %d bytes, but read %d (%v)", written, read, err)
+			}
+			if !b.Equal(a) {
+				t.Errorf("Set of length %d should round-trip through WriteTo and ReadFrom", n)
+			}
+			if c := cap(b.b); c != int(wordsNeeded64(n)) {
+				t.Errorf("ReadFrom should allocate exactly %d words, not %d", wordsNeeded64(n), c)
+			}
+		}
+		if _, err = New64(0).ReadFrom(bytes.NewReader(data[:len(data)-1])); err == nil {
+			t.Errorf("ReadFrom of truncated data of length %d should fail", n)
+		}
+	}
+}
+
+func TestReadFromMalformedHeader64(t *testing.T) {
+	// a header claiming the longest possible set, with no words after it
+	data := New64(0).Header()
+	for i := range data {
+		data[i] = 0xff
+	}
+	if _, err := New64(0).ReadFrom(bytes.NewReader(data)); err == nil {
+		t.Error("ReadFrom of a header with no words should fail")
+	}
+}
+
+func TestCharacteristicVector64(t *testing.T) {
+	a := New64(100)
+	a.Set(1)
+	a.Set(50)
+	a.Set(99)
+	v := a.AsCharacteristicVector(60)
+	f := a.ToFloat32Vector(60)
+	if len(v) != 60 || len(f) != 60 {
+		t.Fatalf("Vectors should be of length 60, not %d and %d", len(v), len(f))
+	}
+	for i := range v {
+		want := 0.0
+		if a.Test(uint64(i)) {
+			want = 1
+		}
+		if v[i] != want || f[i] != float32(want) {
+			t.Errorf("Entry %d should be %f, not %f and %f", i, want, v[i], f[i])
+		}
+	}
+}
+
+func TestJSON64(t *testing.T) {
+	type config struct {
+		Name string
+		Set  *Bitset64
+	}
+	for _, n := range []uint64{0, 100} {
+		a := New64(n)
+		for i := uint64(0); i < n; i += 7 {
+			a.Set(i)
+		}
+		data, err := json.Marshal(config{"test", a})
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		var c config
+		if err = json.Unmarshal(data, &c); err != nil {
+			t.Fatalf("Unmarshal of %s failed: %v", data, err)
+		}
+		if c.Name != "test" || c.Set == nil || !c.Set.Equal(a) {
+			t.Errorf("Set of length %d should round-trip through %s", n, data)
+		}
+	}
+	var b Bitset64
+	if err := json.Unmarshal([]byte(`{"n":100,"bits":"AAAA"}`), &b); err == nil {
+		t.Error("Unmarshal of too few words should fail")
+	}
+	var v struct{ Set Bitset64 }
+	v.Set.Set(5)
+	if err := json.Unmarshal([]byte(`{"Set":null}`), &v); err != nil || !v.Set.Test(5) {
+		t.Errorf("Unmarshal of null should leave the set unchanged (%v)", err)
+	}
+}
+
+func TestTestBlock64(t *testing.T) {
+	a := New64(1000)
+	a.Set(512)
+	a.Set(999)
+	for block := uint64(0); block < 10; block++ {
+		if want := block == 4 || block == 7; a.TestBlock(block, 128) != want {
+			t.Errorf("TestBlock(%d, 128) should be %v", block, want)
+		}
+	}
+	if a.TestBlock(math.MaxUint64, 2) {
+		t.Error("TestBlock beyond the length should be false")
+	}
+}
+
+func TestGob64(t *testing.T) {
+	a := New64(1000)
+	for i := uint64(0); i < 1000; i += 7 {
+		a.Set(i)
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(a); err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	b := New64(0)
+	if err := gob.NewDecoder(&buf).Decode(b); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if !b.Equal(a) {
+		t.Error("Set should round-trip through gob")
+	}
+}
+
+func TestSetBlock64(t *testing.T) {
+	a := New64(1000)
+	a.SetBlock(3, 100)
+	if c := a.Count(); c != 100 || !a.Test(300) || !a.Test(399) {
+		t.Errorf("SetBlock(3, 100) should set bits 300 to 399: %d set", c)
+	}
+	if !a.BlockIsFull(3, 100) || a.BlockIsFull(2, 100) || a.BlockIsFull(1, 300) {
+		t.Error("Only block 3 of 100 bits should be full")
+	}
+	a.SetBlock(10, 100)
+	if l := a.Len(); l != 1100 || !a.BlockIsFull(10, 100) {
+		t.Errorf("SetBlock beyond the length should expand the set to 1100, not %d", l)
+	}
+	if a.BlockIsFull(5, 200) {
+		t.Error("Block extending beyond the length should not be full")
+	}
+}
+
+func TestBytes64(t *testing.T) {
+	for _, n := range []uint64{0, 1, 33, 100, 128} {
+		a := New64(n)
+		for i := uint64(0); i < n; i += 3 {
+			a.Set(i)
+		}
+		data := a.Bytes()
+		if b := New64FromBytes(a.Len(), data); !b.Equal(a) {
+			t.Errorf("Set of length %d should round-trip through Bytes", n)
+		}
+		for i := range data {
+			data[i] = 0
+		}
+		if n > 0 && !a.Test(0) {
+			t.Error("Changing the bytes should not change the set")
+		}
+	}
+	b := New64FromBytes(100, []byte{0x01, 0x00, 0x80})
+	if c := b.Count(); c != 2 || !b.Test(0) || !b.Test(23) {
+		t.Errorf("Set from short bytes should have bits 0 and 23 set: %s", b)
+	}
+}
+
+func TestPrefixCounts64(t *testing.T) {
+	a := New64(200)
+	for i := uint64(100); i < 200; i += 3 {
+		a.Set(i)
+	}
+	a.Set(5)
+	counts := a.PrefixCounts()
+	if len(counts) != 201 {
+		t.Fatalf("Prefix counts should be of length 201, not %d", len(counts))
+	}
+	for i := uint64(0); i <= 200; i++ {
+		if want := a.CountRange(0, i); counts[i] != want {
+			t.Errorf("Prefix count %d should be %d, not %d", i, want, counts[i])
+		}
+	}
+}
+
+func TestNewFromIndices64(t *testing.T) {
+	a := New64FromIndices(3, 7, 64, 7)
+	if c := a.Count(); c != 3 || !a.Test(3) || !a.Test(7) || !a.Test(64) {
+		t.Errorf("Set should have bits 3, 7 and 64 set: %s", a)
+	}
+	if l := a.Len(); l != 65 {
+		t.Errorf("Set should be of length 65, not %d", l)
+	}
+	if b := New64FromIndices(); b.Len() != 0 || b.Any() {
+		t.Error("Set from no indices should be empty")
+	}
+}
+
+func TestSelectTable64(t *testing.T) {
+	a := New64FromIndices(2, 40, 41, 199)
+	table := a.SelectTable()
+	if len(table) != 4 || table[0] != 2 || table[1] != 40 || table[2] != 41 || table[3] != 199 {
+		t.Errorf("Select table should be [2 40 41 199], not %v", table)
+	}
+	a.Clear(40)
+	if table[1] != 40 {
+		t.Error("Select table should not change when the set does")
+	}
+}
+
+func TestToSlice64(t *testing.T) {
+	a := New64(1000)
+	if s := a.ToSlice(); len(s) != 0 {
+		t.Errorf("Empty set should have no set bits, not %v", s)
+	}
+	for i := uint64(0); i < 1000; i += 37 {
+		a.Set(i)
+	}
+	a.Set(999)
+	var want []uint64
+	for i := uint64(0); i < a.Len(); i++ {
+		if a.Test(i) {
+			want = append(want, i)
+		}
+	}
+	s := a.ToSlice()
+	if len(s) != len(want) {
+		t.Fatalf("ToSlice should return %v, not %v", want, s)
+	}
+	for k := range want {
+		if s[k] != want[k] {
+			t.Fatalf("ToSlice should return %v, not %v", want, s)
+		}
+	}
+}
+
+func TestEach64(t *testing.T) {
+	a := New64(500)
+	for i := uint64(3); i < 500; i += 11 {
+		a.Set(i)
+	}
+	var calls, prev uint64
+	a.Each(func(i uint64) bool {
+		if !a.Test(i) || (calls > 0 && i <= prev) {
+			t.Errorf("Each visited %d out of order or unset", i)
+		}
+		prev = i
+		calls++
+		return true
+	})
+	if calls != a.Count() {
+		t.Errorf("Each should call fn %d times, not %d", a.Count(), calls)
+	}
+}
+
+func TestEachStop64(t *testing.T) {
+	a := New64(500)
+	for i := uint64(3); i < 500; i += 11 {
+		a.Set(i)
+	}
+	var seen []uint64
+	a.Each(func(i uint64) bool {
+		seen = append(seen, i)
+		return len(seen) < 3
+	})
+	if len(seen) != 3 || seen[0] != 3 || seen[1] != 14 || seen[2] != 25 {
+		t.Errorf("Each should stop after [3 14 25], not %v", seen)
+	}
+}
+
+func TestIsSubsetOfWords64(t *testing.T) {
+	a := New64(100)
+	a.Set(1)
+	a.Set(70)
+	if !a.IsSubsetOfWords([]uint64{1<<1 | 1<<5, 1 << 6}) {
+		t.Error("Bitset should be a subset of words containing its bits")
+	}
+	if a.IsSubsetOfWords([]uint64{1 << 1, 0}) {
+		t.Error("Bitset should not be a subset of words missing bit 70")
+	}
+	if a.IsSubsetOfWords([]uint64{1 << 1}) {
+		t.Error("Bitset should not be a subset of a shorter word slice missing bit 70")
+	}
+	if !New64(100).IsSubsetOfWords(nil) {
+		t.Error("Empty bitset should be a subset of anything")
+	}
+}
+
+func TestWeightedJaccard64(t *testing.T) {
+	a := New64(100)
+	b := New64(40)
+	a.Set(1)
+	a.Set(2)
+	a.Set(80)
+	b.Set(2)
+	b.Set(3)
+	weights := []float64{0, 1, 2, 3}
+	// Intersection {2} weighs 2; union {1, 2, 3, 80} weighs 1+2+3+1.
+	if got := a.WeightedJaccard(b, weights); math.Abs(got-2.0/7) > 1e-12 {
+		t.Errorf("WeightedJaccard should be %v, not %v", 2.0/7, got)
+	}
+	if got := a.WeightedJaccard(b, nil); math.Abs(got-a.Tanimoto(b)) > 1e-12 {
+		t.Errorf("Unweighted WeightedJaccard should equal Tanimoto %v, not %v", a.Tanimoto(b), got)
+	}
+	if got := New64(10).WeightedJaccard(New64(10), weights); got != 0 {
+		t.Errorf("WeightedJaccard of empty sets should be 0, not %v", got)
+	}
+}
+
+func TestIntersectionCount64(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for k := 0; k < 20; k++ {
+		a := New64(uint64(r.Intn(300)))
+		b := New64(uint64(r.Intn(300)))
+		for i := 0; i < 100; i++ {
+			if a.Len() > 0 {
+				a.Set(uint64(r.Intn(int(a.Len()))))
+			}
+			if b.Len() > 0 {
+				b.Set(uint64(r.Intn(int(b.Len()))))
+			}
+		}
+		if got, want := a.IntersectionCount(b), a.Intersection(b).Count(); got != want {
+			t.Errorf("IntersectionCount should be %d, not %d", want, got)
+		}
+	}
+}
+
+func TestVerify64(t *testing.T) {
+	a := New64(100)
+	a.Set(3)
+	a.Set(99)
+	data, _ := a.MarshalBinary()
+	if err := Verify64(data); err != nil {
+		t.Errorf("Verify64 should accept a marshaled bitset, but got %v", err)
+	}
+	if err := Verify64(data[:len(data)-1]); err == nil {
+		t.Error("Verify64 should reject truncated words")
+	}
+	if err := Verify64(append(data, 0)); err == nil {
+		t.Error("Verify64 should reject trailing bytes")
+	}
+	if err := Verify64(data[:2]); err == nil {
+		t.Error("Verify64 should reject a truncated header")
+	}
+}
+
+func TestUnionCount64(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for k := 0; k < 20; k++ {
+		a := New64(uint64(r.Intn(300)))
+		b := New64(uint64(r.Intn(300)))
+		for i := 0; i < 100; i++ {
+			if a.Len() > 0 {
+				a.Set(uint64(r.Intn(int(a.Len()))))
+			}
+			if b.Len() > 0 {
+				b.Set(uint64(r.Intn(int(b.Len()))))
+			}
+		}
+		if got, want := a.UnionCount(b), a.Union(b).Count(); got != want {
+			t.Errorf("UnionCount should be %d, not %d", want, got)
+		}
+	}
+}
+
+func TestFillBools64(t *testing.T) {
+	a := New64(40)
+	a.Set(0)
+	a.Set(33)
+	dst := make([]bool, 50)
+	dst[45] = true
+	a.FillBools(dst)
+	for i, v := range dst {
+		if want := i == 0 || i == 33 || i == 45; v != want {
+			t.Errorf("FillBools should leave element %d %v, not %v", i, want, v)
+		}
+	}
+	short := []bool{true, true, true}
+	a.FillBools(short)
+	if !short[0] || short[1] || short[2] {
+		t.Errorf("FillBools into a short slice should give [true false false], not %v", short)
+	}
+}
+
+func TestIntersects64(t *testing.T) {
+	a := New64(100)
+	b := New64(100)
+	a.Set(1)
+	a.Set(70)
+	b.Set(2)
+	b.Set(71)
+	if a.Intersects(b) || b.Intersects(a) {
+		t.Error("Disjoint sets should not intersect")
+	}
+	b.Set(70)
+	if !a.Intersects(b) || !b.Intersects(a) {
+		t.Error("Sets sharing bit 70 should intersect")
+	}
+	c := New64(10)
+	c.Set(1)
+	if !a.Intersects(c) || !c.Intersects(a) {
+		t.Error("Sets of different lengths sharing bit 1 should intersect")
+	}
+	if b.Intersects(c) || c.Intersects(b) {
+		t.Error("Disjoint sets of different lengths should not intersect")
+	}
+}
+
+func TestOverlapMatrix64(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	sets := make([]*Bitset64, 5)
+	for k := range sets {
+		sets[k] = New64(uint64(50 + r.Intn(200)))
+		for i := 0; i < 60; i++ {
+			sets[k].Set(uint64(r.Intn(int(sets[k].Len()))))
+		}
+	}
+	m := OverlapMatrix64(sets)
+	if len(m) != len(sets) {
+		t.Fatalf("OverlapMatrix64 should have %d rows, not %d", len(sets), len(m))
+	}
+	for i := range sets {
+		for j := range sets {
+			if want := sets[i].Intersection(sets[j]).Count(); m[i][j] != want {
+				t.Errorf("OverlapMatrix64[%d][%d] should be %d, not %d", i, j, want, m[i][j])
+			}
+		}
+	}
+	if m := OverlapMatrix64(nil); len(m) != 0 {
+		t.Errorf("OverlapMatrix64 of no sets should be empty, not %v", m)
+	}
+}
+
+func TestIsSubset64(t *testing.T) {
+	a := New64(100)
+	b := New64(100)
+	a.Set(1)
+	a.Set(70)
+	b.Set(1)
+	b.Set(70)
+	if !a.IsSubset(b) || !a.IsSuperset(b) {
+		t.Error("Equal sets should be both subset and superset of each other")
+	}
+	b.Set(5)
+	if !a.IsSubset(b) || a.IsSuperset(b) || b.IsSubset(a) || !b.IsSuperset(a) {
+		t.Error("Receiver should be a strict subset of a set with an extra bit")
+	}
+	c := New64(100)
+	c.Set(2)
+	if a.IsSubset(c) || a.IsSuperset(c) {
+		t.Error("Disjoint sets should be neither subset nor superset")
+	}
+	short := New64(10)
+	short.Set(1)
+	if a.IsSubset(short) || !short.IsSubset(a) {
+		t.Error("A longer set with tail bits should not be a subset of a shorter set")
+	}
+}
+
+func TestThin64(t *testing.T) {
+	a := New64(1000)
+	for i := uint64(0); i < 1000; i += 2 {
+		a.Set(i)
+	}
+	orig := a.Clone()
+	if cleared := a.Thin(0.1, rand.NewSource(1)); cleared != 400 {
+		t.Errorf("Thin should clear 400 bits, not %d", cleared)
+	}
+	if a.Count() != 100 {
+		t.Errorf("Thinned bitset should have 100 bits set, not %d", a.Count())
+	}
+	if !a.IsSubset(orig) {
+		t.Error("Thin should only clear bits that were set")
+	}
+	b := orig.Clone()
+	b.Thin(0.1, rand.NewSource(1))
+	if !a.Equal(b) {
+		t.Error("Thin with the same source should clear the same bits")
+	}
+	if cleared := a.Thin(0.5, rand.NewSource(1)); cleared != 0 {
+		t.Errorf("Thin to a higher density should clear nothing, not %d", cleared)
+	}
+	if cleared := a.Thin(0, rand.NewSource(1)); cleared != 100 || a.Count() != 0 {
+		t.Errorf("Thin to 0 should clear all 100 bits, not %d", cleared)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Thin with a density above 1 should panic")
+		}
+	}()
+	a.Thin(1.5, rand.NewSource(1))
+}
+
+func TestResize64(t *testing.T) {
+	a := New64(100)
+	a.Set(3)
+	a.Set(40)
+	a.Set(99)
+	a.Resize(41)
+	if a.Len() != 41 {
+		t.Errorf("Resized bitset should have length 41, not %d", a.Len())
+	}
+	if !a.Test(3) || !a.Test(40) || a.Count() != 2 {
+		t.Error("Resize should keep the bits below the new length")
+	}
+	a.Resize(35)
+	if a.Test(40) || a.Count() != 1 {
+		t.Error("Resize should discard the bits beyond the new length")
+	}
+	a.Resize(200)
+	if a.Len() != 200 {
+		t.Errorf("Regrown bitset should have length 200, not %d", a.Len())
+	}
+	if a.Test(40) || a.Test(99) || a.Count() != 1 {
+		t.Error("Regrown bits should be clear")
+	}
+	a.Resize(0)
+	if a.Len() != 0 || a.Count() != 0 {
+		t.Error("Bitset resized to 0 should be empty")
+	}
+	a.Set(70)
+	if a.Len() != 71 || a.Count() != 1 {
+		t.Error("Bitset resized to 0 should grow again on Set")
+	}
+}
+
+func TestPushPop64(t *testing.T) {
+	a := New64(0)
+	vals := make([]bool, 70)
+	for i := range vals {
+		vals[i] = i%3 == 0
+		a.Push(vals[i])
+	}
+	if a.Len() != 70 {
+		t.Errorf("Bitset should have length 70 after 70 pushes, not %d", a.Len())
+	}
+	for i, v := range vals {
+		if a.Test(uint64(i)) != v {
+			t.Errorf("Pushed bit %d should be %v", i, v)
+		}
+	}
+	for i := len(vals) - 1; i >= 0; i-- {
+		v, ok := a.Pop()
+		if !ok || v != vals[i] {
+			t.Errorf("Pop should return %v, true for bit %d, not %v, %v", vals[i], i, v, ok)
+		}
+		if a.Len() != uint64(i) {
+			t.Errorf("Bitset should have length %d after pop, not %d", i, a.Len())
+		}
+	}
+	if _, ok := a.Pop(); ok {
+		t.Error("Pop on an empty bitset should return false")
+	}
+	a.Push(false)
+	if a.Len() != 1 || a.Test(0) {
+		t.Error("Pushing false after popping a set bit should leave it clear")
+	}
+}
+
+func TestCompact64(t *testing.T) {
+	a := New64(10)
+	a.Set(5)
+	a.Set(40)
+	a.Set(1000)
+	a.Clear(1000)
+	a.Compact()
+	if a.Len() != 41 {
+		t.Errorf("Compacted bitset should have length 41, not %d", a.Len())
+	}
+	if !a.Test(5) || !a.Test(40) || a.Count() != 2 {
+		t.Error("Compact should keep the remaining set bits")
+	}
+	a.Clear(5)
+	a.Clear(40)
+	a.Compact()
+	if a.Len() != 0 || a.Count() != 0 {
+		t.Errorf("Compacted empty bitset should have length 0, not %d", a.Len())
+	}
+	a.Set(3)
+	if a.Len() != 4 || !a.Test(3) {
+		t.Error("Compacted bitset should grow again on Set")
+	}
+}
 
 func BenchmarkSet64(b *testing.B) {
 	b.StopTimer()
@@ -295,3 +2533,64 @@ func BenchmarkSetExpand64(b *testing.B) {
 		s.Set(sz)
 	}
 }
+
+func BenchmarkUnion64(b *testing.B) {
+	b.StopTimer()
+	r := rand.New(rand.NewSource(0))
+	sz := int64(100000)
+	s := New64(uint64(sz))
+	o := New64(uint64(sz))
+	for i := 0; i < 1000; i++ {
+		o.Set(uint64(r.Int63n(sz)))
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		s = s.Union(o)
+	}
+}
+
+func BenchmarkUnionWith64(b *testing.B) {
+	b.StopTimer()
+	r := rand.New(rand.NewSource(0))
+	sz := int64(100000)
+	s := New64(uint64(sz))
+	o := New64(uint64(sz))
+	for i := 0; i < 1000; i++ {
+		o.Set(uint64(r.Int63n(sz)))
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		s.UnionWith(o)
+	}
+}
+
+func BenchmarkCount64(b *testing.B) {
+	b.StopTimer()
+	r := rand.New(rand.NewSource(0))
+	sz := int64(1000000)
+	s := New64(uint64(sz))
+	for i := 0; i < 200000; i++ {
+		s.Set(uint64(r.Int63n(sz)))
+	}
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		s.Count()
+	}
+}
+
+func BenchmarkIntersectionCount64(b *testing.B) {
+	b.StopTimer()
+	r := rand.New(rand.NewSource(0))
+	sz := int64(100000)
+	s := New64(uint64(sz))
+	o := New64(uint64(sz))
+	for i := 0; i < 1000; i++ {
+		s.Set(uint64(r.Int63n(sz)))
+		o.Set(uint64(r.Int63n(sz)))
+	}
+	b.ReportAllocs()
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		s.IntersectionCount(o)
+	}
+}